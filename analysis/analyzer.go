@@ -15,5 +15,28 @@
  */
 package analysis
 
+import (
+	"github.com/gobenpark/trader/broker"
+	"github.com/gobenpark/trader/container"
+	"github.com/gobenpark/trader/order"
+	"github.com/gobenpark/trader/trade"
+)
+
+// Result is named values which analyzer produce at the end of run
+type Result map[string]float64
+
+// Analyzer observe run of cerebro and produce Result when it is stopped
+// embed DefaultAnalyzer to implement only needed callbacks
 type Analyzer interface {
+	// Name is key of analyzer result in cerebro
+	Name() string
+	// Next is called with container after every bar
+	Next(c container.Container)
+	NotifyOrder(o *order.Order)
+	NotifyTrade(t *trade.Trade)
+	// NotifyFund is called with account snapshot after every bar and execution
+	NotifyFund(f *broker.Fund)
+	// Stop is called once at the end of run, analyzer calculate result here
+	Stop()
+	Result() Result
 }
@@ -0,0 +1,158 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package analysis
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/gobenpark/trader/broker"
+	"github.com/gobenpark/trader/container"
+	"github.com/gobenpark/trader/position"
+	"github.com/gobenpark/trader/trade"
+	"github.com/stretchr/testify/assert"
+)
+
+var start = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func feed(a Analyzer, values ...float64) {
+	for i, v := range values {
+		a.NotifyFund(&broker.Fund{Date: start.AddDate(0, 0, i), Value: v})
+	}
+	a.Stop()
+}
+
+func TestReturns(t *testing.T) {
+	r := NewReturns()
+	r.NotifyFund(&broker.Fund{Date: start, Value: 100})
+	r.NotifyFund(&broker.Fund{Date: start.Add(year), Value: 110})
+	r.NotifyFund(&broker.Fund{Date: start.Add(2 * year), Value: 90})
+	r.NotifyFund(&broker.Fund{Date: start.Add(2 * year), Value: 121})
+	r.Stop()
+
+	assert.InDelta(t, 0.21, r.Result()["total"], 1e-9)
+	assert.InDelta(t, 0.1, r.Result()["cagr"], 1e-9)
+}
+
+func TestSharpeRatio(t *testing.T) {
+	s := NewSharpeRatio(0, 4)
+	feed(s, 100, 110, 99, 108.9)
+
+	// returns are 0.1, -0.1, 0.1
+	ret := []float64{0.1, -0.1, 0.1}
+	expected := mean(ret) / stddev(ret) * 2
+	assert.InDelta(t, expected, s.Result()["sharpe"], 1e-9)
+	assert.InDelta(t, 0.57735, expected, 1e-5)
+}
+
+func TestSortinoRatio(t *testing.T) {
+	s := NewSortinoRatio(0, 4)
+	feed(s, 100, 110, 99, 108.9)
+
+	downside := math.Sqrt(0.01 / 3)
+	assert.InDelta(t, 0.1/3/downside*2, s.Result()["sortino"], 1e-9)
+}
+
+func TestDrawDown(t *testing.T) {
+	d := NewDrawDown()
+	feed(d, 100, 120, 90, 100, 130, 117, 125)
+
+	assert.InDelta(t, 0.25, d.MaxDrawDown, 1e-9)
+	assert.Equal(t, float64(30), d.MaxMoney)
+	assert.Equal(t, 2, d.MaxLen)
+	assert.Equal(t, 48*time.Hour, d.MaxDuration)
+}
+
+func TestTradeAnalyzer(t *testing.T) {
+	a := NewTradeAnalyzer()
+	for _, pnl := range []float64{30, -10, 20, -5} {
+		tr := trade.New("code", 1, 100, 0, start)
+		tr.Update(-1, 100+pnl, 0, start)
+		a.NotifyTrade(tr)
+	}
+	a.NotifyTrade(trade.New("code", 1, 100, 0, start))
+	a.Stop()
+
+	assert.Equal(t, 4, a.Total)
+	assert.Equal(t, 0.5, a.WinRate)
+	assert.InDelta(t, 50.0/15.0, a.ProfitFactor, 1e-9)
+}
+
+func TestExposure(t *testing.T) {
+	e := NewExposure()
+	for i := 0; i <= 10; i++ {
+		c := container.NewDataContainer(container.Info{Code: "code"})
+		c.Add(container.Candle{Code: "code", Date: start.AddDate(0, 0, i)})
+		e.Next(c)
+	}
+
+	first := trade.New("a", 1, 100, 0, start.AddDate(0, 0, 1))
+	first.Update(-1, 100, 0, start.AddDate(0, 0, 4))
+	e.NotifyTrade(first)
+
+	overlap := trade.New("b", 1, 100, 0, start.AddDate(0, 0, 3))
+	overlap.Update(-1, 100, 0, start.AddDate(0, 0, 5))
+	e.NotifyTrade(overlap)
+
+	e.NotifyTrade(trade.New("c", 1, 100, 0, start.AddDate(0, 0, 8)))
+	e.Stop()
+
+	assert.InDelta(t, 0.6, e.Result()["exposure"], 1e-9)
+}
+
+func TestEquity(t *testing.T) {
+	e := NewEquity()
+	e.NotifyFund(&broker.Fund{Date: start, Cash: 1000, Value: 1000})
+	e.NotifyFund(&broker.Fund{Date: start.AddDate(0, 0, 1), Cash: 500, Value: 1000})
+	e.NotifyFund(&broker.Fund{Date: start.AddDate(0, 0, 1), Cash: 500, Value: 1100, Positions: []position.Position{
+		{Code: "a", Size: 5, MarketPrice: 120},
+	}})
+	e.NotifyFund(&broker.Fund{Date: start.AddDate(0, 0, 2), Cash: 200, Value: 880, Positions: []position.Position{
+		{Code: "a", Size: 5, MarketPrice: 100},
+		{Code: "b", Size: -2, MarketPrice: 60},
+	}})
+	e.Stop()
+
+	curve := e.Curve()
+	assert.Len(t, curve, 3)
+	assert.Equal(t, float64(600), curve[1].PositionValue)
+	assert.Equal(t, float64(600), curve[1].Positions["a"])
+	assert.Equal(t, float64(-120), curve[2].Positions["b"])
+	assert.Equal(t, []float64{0, 0, 0.2}, e.DrawDowns())
+	assert.Equal(t, Result{"final": 880, "peak": 1100, "max_drawdown": 0.2}, e.Result())
+
+	var buf bytes.Buffer
+	assert.NoError(t, e.WriteCSV(&buf))
+	assert.Equal(t, "date,cash,position,equity,drawdown,a,b\n"+
+		"2021-01-01T00:00:00Z,1000,0,1000,0,0,0\n"+
+		"2021-01-02T00:00:00Z,500,600,1100,0,600,0\n"+
+		"2021-01-03T00:00:00Z,200,680,880,0.2,500,-120\n", buf.String())
+}
+
+func TestEquity_Execution(t *testing.T) {
+	e := NewEquity()
+	e.NotifyFund(&broker.Fund{Date: start, Cash: 1000, Value: 1000})
+	e.NotifyFund(&broker.Fund{Date: start.Add(time.Hour), Cash: 500, Value: 1000, Execution: true})
+	e.NotifyFund(&broker.Fund{Date: start.AddDate(0, 0, 1), Cash: 500, Value: 1050})
+
+	// snapshot after execution in bar is replaced with snapshot at close of the bar
+	curve := e.Curve()
+	assert.Len(t, curve, 2)
+	assert.Equal(t, start.AddDate(0, 0, 1), curve[1].Date)
+	assert.Equal(t, float64(1050), curve[1].Equity)
+}
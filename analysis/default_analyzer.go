@@ -1,18 +1,37 @@
 /*
- *                     GNU GENERAL PUBLIC LICENSE
- *                        Version 3, 29 June 2007
+ *  Copyright 2021 The Trader Authors
  *
- *  Copyright (C) 2007 Free Software Foundation, Inc. <https://fsf.org/>
- *  Everyone is permitted to copy and distribute verbatim copies
- *  of this license document, but changing it is not allowed.
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
  *
- *                             Preamble
+ *      <https:fsf.org/>
  *
- *   The GNU General Public License is a free, copyleft license for
- * software and other kinds of works.
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
  */
-
 package analysis
 
+import (
+	"github.com/gobenpark/trader/broker"
+	"github.com/gobenpark/trader/container"
+	"github.com/gobenpark/trader/order"
+	"github.com/gobenpark/trader/trade"
+)
+
+// DefaultAnalyzer ignore every callback
 type DefaultAnalyzer struct {
 }
+
+func (d *DefaultAnalyzer) Next(c container.Container) {}
+
+func (d *DefaultAnalyzer) NotifyOrder(o *order.Order) {}
+
+func (d *DefaultAnalyzer) NotifyTrade(t *trade.Trade) {}
+
+func (d *DefaultAnalyzer) NotifyFund(f *broker.Fund) {}
+
+func (d *DefaultAnalyzer) Stop() {}
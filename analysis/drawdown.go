@@ -0,0 +1,79 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package analysis
+
+import (
+	"time"
+
+	"github.com/gobenpark/trader/broker"
+)
+
+// DrawDown analyze largest decline of account value from its peak
+// MaxDrawDown is rate of decline, MaxDuration and MaxLen are longest time and bars under peak
+type DrawDown struct {
+	DefaultAnalyzer
+	series
+	MaxDrawDown float64
+	MaxMoney    float64
+	MaxDuration time.Duration
+	MaxLen      int
+}
+
+func NewDrawDown() *DrawDown {
+	return &DrawDown{}
+}
+
+func (d *DrawDown) Name() string {
+	return "drawdown"
+}
+
+func (d *DrawDown) NotifyFund(f *broker.Fund) {
+	d.add(f)
+}
+
+func (d *DrawDown) Stop() {
+	peak := 0
+	for i, v := range d.values {
+		if v >= d.values[peak] {
+			peak = i
+			continue
+		}
+
+		money := d.values[peak] - v
+		if money > d.MaxMoney {
+			d.MaxMoney = money
+		}
+		if d.values[peak] != 0 && money/d.values[peak] > d.MaxDrawDown {
+			d.MaxDrawDown = money / d.values[peak]
+		}
+		if i-peak > d.MaxLen {
+			d.MaxLen = i - peak
+		}
+		if du := d.dates[i].Sub(d.dates[peak]); du > d.MaxDuration {
+			d.MaxDuration = du
+		}
+	}
+}
+
+// Result max_drawdown_duration is seconds
+func (d *DrawDown) Result() Result {
+	return Result{
+		"max_drawdown":          d.MaxDrawDown,
+		"max_drawdown_money":    d.MaxMoney,
+		"max_drawdown_len":      float64(d.MaxLen),
+		"max_drawdown_duration": d.MaxDuration.Seconds(),
+	}
+}
@@ -0,0 +1,82 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package analysis
+
+import (
+	"sync"
+
+	"github.com/gobenpark/trader/broker"
+	"github.com/gobenpark/trader/container"
+	"github.com/gobenpark/trader/order"
+	"github.com/gobenpark/trader/trade"
+)
+
+// Engine deliver bars and events to analyzers
+// calls are serialized, so analyzer don't need lock
+type Engine struct {
+	mu        sync.Mutex
+	once      sync.Once
+	Analyzers []Analyzer
+}
+
+func (e *Engine) Next(c container.Container) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, a := range e.Analyzers {
+		a.Next(c)
+	}
+}
+
+func (e *Engine) Listen(evt interface{}) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	switch et := evt.(type) {
+	case *order.Order:
+		for _, a := range e.Analyzers {
+			a.NotifyOrder(et)
+		}
+	case *trade.Trade:
+		for _, a := range e.Analyzers {
+			a.NotifyTrade(et)
+		}
+	case *broker.Fund:
+		for _, a := range e.Analyzers {
+			a.NotifyFund(et)
+		}
+	}
+}
+
+// Stop finish every analyzer only once
+func (e *Engine) Stop() {
+	e.once.Do(func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		for _, a := range e.Analyzers {
+			a.Stop()
+		}
+	})
+}
+
+// Results is result of every analyzer by its name
+func (e *Engine) Results() map[string]Result {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	results := make(map[string]Result)
+	for _, a := range e.Analyzers {
+		results[a.Name()] = a.Result()
+	}
+	return results
+}
@@ -0,0 +1,151 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package analysis
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gobenpark/trader/broker"
+)
+
+// Point is account snapshot of one bar
+// Positions is market value of each code, DrawDown is decline rate of Equity from its peak
+type Point struct {
+	Date          time.Time          `json:"date"`
+	Cash          float64            `json:"cash"`
+	PositionValue float64            `json:"positionValue"`
+	Equity        float64            `json:"equity"`
+	DrawDown      float64            `json:"drawdown"`
+	Positions     map[string]float64 `json:"positions"`
+}
+
+// Equity record equity curve and drawdown series of run
+// snapshots at same time are merged to latest one, and snapshot after execution is replaced
+// by following snapshot at close of bar, so there is one point per bar
+type Equity struct {
+	DefaultAnalyzer
+	points []Point
+	// execution is true if last point is snapshot after execution
+	execution bool
+}
+
+func NewEquity() *Equity {
+	return &Equity{}
+}
+
+func (e *Equity) Name() string {
+	return "equity"
+}
+
+func (e *Equity) NotifyFund(f *broker.Fund) {
+	p := Point{
+		Date:          f.Date,
+		Cash:          f.Cash,
+		PositionValue: f.Value - f.Cash,
+		Equity:        f.Value,
+		Positions:     make(map[string]float64),
+	}
+	for _, i := range f.Positions {
+		p.Positions[i.Code] = i.Value()
+	}
+
+	execution := e.execution
+	e.execution = f.Execution
+	if n := len(e.points); n > 0 && (execution || !f.Date.After(e.points[n-1].Date)) {
+		e.points[n-1] = p
+		return
+	}
+	e.points = append(e.points, p)
+}
+
+// Curve is equity time series with drawdown
+func (e *Equity) Curve() []Point {
+	curve := make([]Point, len(e.points))
+	peak := 0.0
+	for i, p := range e.points {
+		if p.Equity > peak {
+			peak = p.Equity
+		}
+		if peak > 0 {
+			p.DrawDown = (peak - p.Equity) / peak
+		}
+		curve[i] = p
+	}
+	return curve
+}
+
+// DrawDowns is drawdown series of equity curve
+func (e *Equity) DrawDowns() []float64 {
+	var dd []float64
+	for _, p := range e.Curve() {
+		dd = append(dd, p.DrawDown)
+	}
+	return dd
+}
+
+func (e *Equity) Result() Result {
+	r := Result{"final": 0, "peak": 0, "max_drawdown": 0}
+	for _, p := range e.Curve() {
+		r["final"] = p.Equity
+		if p.Equity > r["peak"] {
+			r["peak"] = p.Equity
+		}
+		if p.DrawDown > r["max_drawdown"] {
+			r["max_drawdown"] = p.DrawDown
+		}
+	}
+	return r
+}
+
+// WriteCSV write equity curve with header, market value of each code follow after fixed columns
+func (e *Equity) WriteCSV(w io.Writer) error {
+	curve := e.Curve()
+	var codes []string
+	seen := make(map[string]bool)
+	for _, p := range curve {
+		for code := range p.Positions {
+			if !seen[code] {
+				seen[code] = true
+				codes = append(codes, code)
+			}
+		}
+	}
+	sort.Strings(codes)
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(append([]string{"date", "cash", "position", "equity", "drawdown"}, codes...)); err != nil {
+		return err
+	}
+	for _, p := range curve {
+		row := []string{p.Date.Format(time.RFC3339), format(p.Cash), format(p.PositionValue), format(p.Equity), format(p.DrawDown)}
+		for _, code := range codes {
+			row = append(row, format(p.Positions[code]))
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func format(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
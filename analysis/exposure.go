@@ -0,0 +1,101 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package analysis
+
+import (
+	"sort"
+	"time"
+
+	"github.com/gobenpark/trader/container"
+	"github.com/gobenpark/trader/trade"
+)
+
+// Exposure is rate of time which any trade is open during run
+// trades of different codes at same time are counted once
+type Exposure struct {
+	DefaultAnalyzer
+	first, last time.Time
+	// periods open and close time of trades, open trade has zero close time
+	periods map[string][2]time.Time
+	Rate    float64
+}
+
+func NewExposure() *Exposure {
+	return &Exposure{periods: make(map[string][2]time.Time)}
+}
+
+func (e *Exposure) Name() string {
+	return "exposure"
+}
+
+func (e *Exposure) Next(c container.Container) {
+	values := c.Values()
+	if len(values) == 0 {
+		return
+	}
+
+	date := values[0].Date
+	if e.first.IsZero() || date.Before(e.first) {
+		e.first = date
+	}
+	if date.After(e.last) {
+		e.last = date
+	}
+}
+
+func (e *Exposure) NotifyTrade(t *trade.Trade) {
+	e.periods[t.ID] = [2]time.Time{t.OpenedAt, t.ClosedAt}
+}
+
+func (e *Exposure) Stop() {
+	total := e.last.Sub(e.first)
+	if total <= 0 {
+		return
+	}
+
+	var periods [][2]time.Time
+	for _, p := range e.periods {
+		if p[1].IsZero() || p[1].After(e.last) {
+			p[1] = e.last
+		}
+		if p[0].Before(e.first) {
+			p[0] = e.first
+		}
+		if p[1].After(p[0]) {
+			periods = append(periods, p)
+		}
+	}
+	sort.Slice(periods, func(i, j int) bool {
+		return periods[i][0].Before(periods[j][0])
+	})
+
+	var exposed time.Duration
+	var end time.Time
+	for _, p := range periods {
+		if p[0].Before(end) {
+			p[0] = end
+		}
+		if p[1].After(p[0]) {
+			exposed += p[1].Sub(p[0])
+			end = p[1]
+		}
+	}
+	e.Rate = float64(exposed) / float64(total)
+}
+
+func (e *Exposure) Result() Result {
+	return Result{"exposure": e.Rate}
+}
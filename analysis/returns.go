@@ -0,0 +1,59 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package analysis
+
+import (
+	"math"
+
+	"github.com/gobenpark/trader/broker"
+)
+
+// Returns analyze total return and compound annual growth rate of account value
+type Returns struct {
+	DefaultAnalyzer
+	series
+	Total float64
+	CAGR  float64
+}
+
+func NewReturns() *Returns {
+	return &Returns{}
+}
+
+func (r *Returns) Name() string {
+	return "returns"
+}
+
+func (r *Returns) NotifyFund(f *broker.Fund) {
+	r.add(f)
+}
+
+func (r *Returns) Stop() {
+	n := len(r.values)
+	if n < 2 || r.values[0] == 0 {
+		return
+	}
+
+	growth := r.values[n-1] / r.values[0]
+	r.Total = growth - 1
+	if years := float64(r.dates[n-1].Sub(r.dates[0])) / float64(year); years > 0 && growth > 0 {
+		r.CAGR = math.Pow(growth, 1/years) - 1
+	}
+}
+
+func (r *Returns) Result() Result {
+	return Result{"total": r.Total, "cagr": r.CAGR}
+}
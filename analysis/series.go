@@ -0,0 +1,104 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package analysis
+
+import (
+	"math"
+	"time"
+
+	"github.com/gobenpark/trader/broker"
+)
+
+const year = 365.25 * 24 * time.Hour
+
+// series is account value of each time
+// several funds at same time like execution and bar close are merged to latest one
+type series struct {
+	dates  []time.Time
+	values []float64
+}
+
+func (s *series) add(f *broker.Fund) {
+	if n := len(s.dates); n > 0 && !f.Date.After(s.dates[n-1]) {
+		s.values[n-1] = f.Value
+		return
+	}
+	s.dates = append(s.dates, f.Date)
+	s.values = append(s.values, f.Value)
+}
+
+// returns is rate of change between each value
+func (s *series) returns() []float64 {
+	var r []float64
+	for i := 1; i < len(s.values); i++ {
+		if s.values[i-1] == 0 {
+			r = append(r, 0)
+			continue
+		}
+		r = append(r, s.values[i]/s.values[i-1]-1)
+	}
+	return r
+}
+
+// periods is count of series interval in a year
+func (s *series) periods() float64 {
+	n := len(s.dates)
+	if n < 2 {
+		return 0
+	}
+	interval := s.dates[n-1].Sub(s.dates[0]) / time.Duration(n-1)
+	if interval <= 0 {
+		return 0
+	}
+	return float64(year) / float64(interval)
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// stddev is sample standard deviation
+func stddev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	m := mean(values)
+	sum := 0.0
+	for _, v := range values {
+		sum += (v - m) * (v - m)
+	}
+	return math.Sqrt(sum / float64(len(values)-1))
+}
+
+// excess subtract risk free rate of one period from returns
+func excess(returns []float64, riskFree, periods float64) []float64 {
+	rf := 0.0
+	if periods != 0 {
+		rf = math.Pow(1+riskFree, 1/periods) - 1
+	}
+	ex := make([]float64, len(returns))
+	for i, r := range returns {
+		ex[i] = r - rf
+	}
+	return ex
+}
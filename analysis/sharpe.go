@@ -0,0 +1,60 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package analysis
+
+import (
+	"math"
+
+	"github.com/gobenpark/trader/broker"
+)
+
+// SharpeRatio is annualized excess return per volatility of account value
+// RiskFree is annual rate, Periods is count of bars in a year, zero is inferred from bar interval
+type SharpeRatio struct {
+	DefaultAnalyzer
+	series
+	RiskFree float64
+	Periods  float64
+	Ratio    float64
+}
+
+func NewSharpeRatio(riskFree, periods float64) *SharpeRatio {
+	return &SharpeRatio{RiskFree: riskFree, Periods: periods}
+}
+
+func (s *SharpeRatio) Name() string {
+	return "sharpe"
+}
+
+func (s *SharpeRatio) NotifyFund(f *broker.Fund) {
+	s.add(f)
+}
+
+func (s *SharpeRatio) Stop() {
+	periods := s.Periods
+	if periods == 0 {
+		periods = s.periods()
+	}
+
+	ex := excess(s.returns(), s.RiskFree, periods)
+	if sd := stddev(ex); sd != 0 {
+		s.Ratio = mean(ex) / sd * math.Sqrt(periods)
+	}
+}
+
+func (s *SharpeRatio) Result() Result {
+	return Result{"sharpe": s.Ratio}
+}
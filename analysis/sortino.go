@@ -0,0 +1,69 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package analysis
+
+import (
+	"math"
+
+	"github.com/gobenpark/trader/broker"
+)
+
+// SortinoRatio is like SharpeRatio but only downside deviation is considered as risk
+type SortinoRatio struct {
+	DefaultAnalyzer
+	series
+	RiskFree float64
+	Periods  float64
+	Ratio    float64
+}
+
+func NewSortinoRatio(riskFree, periods float64) *SortinoRatio {
+	return &SortinoRatio{RiskFree: riskFree, Periods: periods}
+}
+
+func (s *SortinoRatio) Name() string {
+	return "sortino"
+}
+
+func (s *SortinoRatio) NotifyFund(f *broker.Fund) {
+	s.add(f)
+}
+
+func (s *SortinoRatio) Stop() {
+	periods := s.Periods
+	if periods == 0 {
+		periods = s.periods()
+	}
+
+	ex := excess(s.returns(), s.RiskFree, periods)
+	if len(ex) == 0 {
+		return
+	}
+
+	downside := 0.0
+	for _, r := range ex {
+		if r < 0 {
+			downside += r * r
+		}
+	}
+	if downside = math.Sqrt(downside / float64(len(ex))); downside != 0 {
+		s.Ratio = mean(ex) / downside * math.Sqrt(periods)
+	}
+}
+
+func (s *SortinoRatio) Result() Result {
+	return Result{"sortino": s.Ratio}
+}
@@ -0,0 +1,84 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package analysis
+
+import (
+	"math"
+
+	"github.com/gobenpark/trader/trade"
+)
+
+// TradeAnalyzer analyze closed trades with profit including commission
+// ProfitFactor is gross profit divided by gross loss, it is infinite when there is no loss
+type TradeAnalyzer struct {
+	DefaultAnalyzer
+	Total        int
+	Won          int
+	Lost         int
+	GrossProfit  float64
+	GrossLoss    float64
+	WinRate      float64
+	ProfitFactor float64
+}
+
+func NewTradeAnalyzer() *TradeAnalyzer {
+	return &TradeAnalyzer{}
+}
+
+func (t *TradeAnalyzer) Name() string {
+	return "trade"
+}
+
+func (t *TradeAnalyzer) NotifyTrade(tr *trade.Trade) {
+	if tr.IsOpen() {
+		return
+	}
+
+	t.Total++
+	pnl := tr.PnLComm()
+	if pnl > 0 {
+		t.Won++
+		t.GrossProfit += pnl
+	} else {
+		t.Lost++
+		t.GrossLoss -= pnl
+	}
+}
+
+func (t *TradeAnalyzer) Stop() {
+	if t.Total != 0 {
+		t.WinRate = float64(t.Won) / float64(t.Total)
+	}
+
+	switch {
+	case t.GrossLoss != 0:
+		t.ProfitFactor = t.GrossProfit / t.GrossLoss
+	case t.GrossProfit != 0:
+		t.ProfitFactor = math.Inf(1)
+	}
+}
+
+func (t *TradeAnalyzer) Result() Result {
+	return Result{
+		"total":         float64(t.Total),
+		"won":           float64(t.Won),
+		"lost":          float64(t.Lost),
+		"gross_profit":  t.GrossProfit,
+		"gross_loss":    t.GrossLoss,
+		"win_rate":      t.WinRate,
+		"profit_factor": t.ProfitFactor,
+	}
+}
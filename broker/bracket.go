@@ -0,0 +1,198 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package broker
+
+import (
+	"github.com/gobenpark/trader/order"
+)
+
+// BuyBracket submit buy entry order with stop loss and take profit sell orders
+// entry zero means market order, otherwise limit order
+// stop loss and take profit are submitted when entry is completed and they are linked as OCO
+// it returns uuid of entry, stop loss and take profit order
+func (b *Broker) BuyBracket(code string, size int64, entry, stop, target float64) (string, string, string) {
+	return b.bracket(order.Buy, code, size, entry, stop, target)
+}
+
+// SellBracket submit sell entry order with stop loss and take profit buy orders
+func (b *Broker) SellBracket(code string, size int64, entry, stop, target float64) (string, string, string) {
+	return b.bracket(order.Sell, code, size, entry, stop, target)
+}
+
+func (b *Broker) bracket(side order.OType, code string, size int64, entry, stop, target float64) (string, string, string) {
+	exit := order.Sell
+	if side == order.Sell {
+		exit = order.Buy
+	}
+
+	exec := order.Limit
+	if entry == 0 {
+		exec = order.Market
+	}
+
+	parent := newOrder(side, code, size, entry, exec)
+	stopLoss := newOrder(exit, code, size, stop, order.Stop)
+	stopLoss.Parent = parent.UUID
+	takeProfit := newOrder(exit, code, size, target, order.Limit)
+	takeProfit.Parent = parent.UUID
+
+	b.mu.Lock()
+	b.orders[stopLoss.UUID] = stopLoss
+	b.orders[takeProfit.UUID] = takeProfit
+	b.children[parent.UUID] = []*order.Order{stopLoss, takeProfit}
+	b.mu.Unlock()
+	b.OCO(stopLoss.UUID, takeProfit.UUID)
+
+	b.Submit(parent)
+	return parent.UUID, stopLoss.UUID, takeProfit.UUID
+}
+
+// OCO link orders as one cancel others
+// when one of orders is completed, canceled or rejected, others are canceled
+func (b *Broker) OCO(uids ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, uid := range uids {
+		b.oco[uid] = uids
+	}
+}
+
+// finish handle linked orders of finished order
+// children of completed order are submitted, and children of failed order are canceled
+// but if failed order is executed partially, children are submitted with executed size
+// other orders of same OCO group are canceled
+func (b *Broker) finish(uid string, completed bool) {
+	b.mu.Lock()
+	if o, ok := b.orders[uid]; ok && !completed {
+		if executed := o.Size - o.Remaining(); executed > 0 {
+			for _, child := range b.children[uid] {
+				child.Resize(executed)
+			}
+			completed = true
+		}
+	}
+	children := b.children[uid]
+	delete(b.children, uid)
+
+	group := b.oco[uid]
+	for _, id := range group {
+		delete(b.oco, id)
+	}
+
+	if !completed {
+		// every child is canceled here, so their OCO group is not needed
+		for _, child := range children {
+			for _, id := range b.oco[child.UUID] {
+				delete(b.oco, id)
+			}
+		}
+	}
+	b.mu.Unlock()
+
+	for _, child := range children {
+		if completed {
+			b.Submit(child)
+			continue
+		}
+		b.canceled(child)
+	}
+
+	for _, id := range group {
+		if id != uid {
+			b.Cancel(id)
+		}
+	}
+}
+
+// partial resize other orders of OCO group to remain size of partially executed order
+// so that they do not execute more than position which is left, like stop loss of partially filled take profit
+func (b *Broker) partial(o *order.Order) {
+	b.mu.Lock()
+	group := b.oco[o.UUID]
+	b.mu.Unlock()
+
+	for _, id := range group {
+		if id != o.UUID {
+			b.resize(id, o.Remaining())
+		}
+	}
+}
+
+// resize change remain size of order
+// stop order and inactive child in broker are changed directly,
+// and order in store is canceled and submitted again with remain size
+func (b *Broker) resize(uid string, remain int64) {
+	b.mu.Lock()
+	o, ok := b.orders[uid]
+	if !ok || !alive(o) || o.Remaining() == remain {
+		b.mu.Unlock()
+		return
+	}
+	held := false
+	for _, s := range b.stops {
+		held = held || s.UUID == uid
+	}
+	for _, child := range b.children[o.Parent] {
+		held = held || child.UUID == uid
+	}
+	o.Resize(o.Size - o.Remaining() + remain)
+	b.mu.Unlock()
+
+	if held {
+		return
+	}
+
+	// triggered stop order is in store as its child
+	replace := &order.Order{OType: o.OType, ExecType: o.ExecType, Code: o.Code, UUID: o.UUID, Size: remain, Price: o.Price, PriceLimit: o.PriceLimit, CreatedAt: o.CreatedAt}
+	if o.ExecType.IsStop() {
+		replace = newStopOrder(replace).child(false)
+	}
+	if err := b.Store.Cancel(uid); err != nil {
+		return
+	}
+	if err := b.Store.Order(replace); err != nil {
+		b.reject(o, err)
+	}
+}
+
+// removeChild remove inactive child order which is waiting for parent
+func (b *Broker) removeChild(uid string) (*order.Order, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	o, ok := b.orders[uid]
+	if !ok || o.Parent == "" {
+		return nil, false
+	}
+
+	children := b.children[o.Parent]
+	for k, child := range children {
+		if child.UUID == uid {
+			b.children[o.Parent] = append(children[:k:k], children[k+1:]...)
+			return child, true
+		}
+	}
+	return nil, false
+}
+
+// alive is true if order can be executed yet
+func alive(o *order.Order) bool {
+	switch o.Status() {
+	case order.Completed, order.Canceled, order.Expired, order.Margin, order.Rejected:
+		return false
+	}
+	return true
+}
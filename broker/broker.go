@@ -17,14 +17,19 @@ package broker
 
 import (
 	"fmt"
+	"math"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/gobenpark/trader/container"
+	error2 "github.com/gobenpark/trader/error"
 	"github.com/gobenpark/trader/event"
 	"github.com/gobenpark/trader/order"
 	"github.com/gobenpark/trader/position"
 	"github.com/gobenpark/trader/store"
+	"github.com/gobenpark/trader/trade"
 	"github.com/satori/go.uuid"
 )
 
@@ -36,97 +41,286 @@ type Broker struct {
 	orders      map[string]*order.Order
 	mu          sync.Mutex
 	eventEngine event.Broadcaster
-	positions   map[string][]position.Position
-	Store       store.Store
+	positions   map[string]*position.Position
+	// trades open trade of each code
+	trades map[string]*trade.Trade
+	// shares count of fund share, it is decided with first fund value
+	shares float64
+	// date time of latest market data, it is used for execution time in backtest
+	date time.Time
+	// stops stop orders waiting for trigger in order of submit
+	stops []*stopOrder
+	// children inactive orders which are submitted when parent order is completed
+	children map[string][]*order.Order
+	// oco groups of linked orders, when one is finished others are canceled
+	oco   map[string][]string
+	Store store.Store
 }
 
 // NewBroker Init new broker with cash,commission
 func NewBroker() *Broker {
 	return &Broker{
 		orders:    make(map[string]*order.Order),
-		positions: make(map[string][]position.Position),
+		positions: make(map[string]*position.Position),
+		trades:    make(map[string]*trade.Trade),
+		children:  make(map[string][]*order.Order),
+		oco:       make(map[string][]string),
 	}
 }
 
-func (b *Broker) Buy(code string, size int64, price float64, exec order.ExecType) string {
-	uid := uuid.NewV4().String()
+// Buy submit buy order, price of stop order is stop price
+// and limit price of StopLimit or trailing distance is given by options
+func (b *Broker) Buy(code string, size int64, price float64, exec order.ExecType, opts ...order.Option) string {
+	o := newOrder(order.Buy, code, size, price, exec, opts...)
+	b.Submit(o)
+	return o.UUID
+}
+
+func (b *Broker) Sell(code string, size int64, price float64, exec order.ExecType, opts ...order.Option) string {
+	o := newOrder(order.Sell, code, size, price, exec, opts...)
+	b.Submit(o)
+	return o.UUID
+}
+
+func newOrder(otype order.OType, code string, size int64, price float64, exec order.ExecType, opts ...order.Option) *order.Order {
 	o := &order.Order{
-		OType:     order.Buy,
+		OType:     otype,
 		Code:      code,
-		UUID:      uid,
+		UUID:      uuid.NewV4().String(),
 		Size:      size,
 		Price:     price,
 		ExecType:  exec,
 		CreatedAt: time.Now(),
 	}
-	b.orders[o.UUID] = o
-	b.Submit(o)
-	return uid
-}
-
-func (b *Broker) Sell(code string, size int64, price float64, exec order.ExecType) string {
-	uid := uuid.NewV4().String()
-	o := &order.Order{
-		Code:     code,
-		UUID:     uid,
-		OType:    order.Sell,
-		Size:     size,
-		Price:    price,
-		ExecType: exec,
+	for _, opt := range opts {
+		opt(o)
 	}
-	b.orders[o.UUID] = o
-	b.Submit(o)
-	return uid
+	return o
 }
 
+// Cancel cancel order which is not completed yet
+// stop order and inactive bracket order are canceled in broker, other orders are canceled in store
 func (b *Broker) Cancel(uid string) {
-	if o, ok := b.orders[uid]; ok {
-		o.Cancel()
-		b.eventEngine.BroadCast(o)
+	if s, ok := b.removeStop(uid); ok {
+		b.canceled(s.Order)
+		return
+	}
+
+	if o, ok := b.removeChild(uid); ok {
+		b.canceled(o)
+		return
+	}
+
+	o, ok := b.getOrder(uid)
+	if !ok || !alive(o) {
+		return
+	}
+
+	if err := b.Store.Cancel(uid); err != nil {
 		return
 	}
+	b.canceled(o)
+}
+
+func (b *Broker) canceled(o *order.Order) {
+	o.Cancel()
+	b.eventEngine.BroadCast(o)
+	b.finish(o.UUID, false)
 }
 
 func (b *Broker) Submit(o *order.Order) {
 	o.Submit()
 	b.eventEngine.BroadCast(o)
 
+	b.mu.Lock()
 	b.orders[o.UUID] = o
+	if o.ExecType.IsStop() {
+		b.stops = append(b.stops, newStopOrder(o))
+		b.mu.Unlock()
+		return
+	}
+	b.mu.Unlock()
+
 	if err := b.Store.Order(o); err != nil {
-		o.Reject(err)
-		b.eventEngine.BroadCast(o)
+		b.reject(o, err)
 		return
 	}
 
 	return
 }
 
+func (b *Broker) reject(o *order.Order, err error) {
+	o.Reject(err)
+	b.eventEngine.BroadCast(o)
+	b.finish(o.UUID, false)
+}
+
+// Accept complete remain size of order with order price
 func (b *Broker) Accept(oid string) {
-	if o, ok := b.orders[oid]; ok {
-		b.positions[o.Code] = append(b.positions[o.Code], position.Position{
-			Code:      o.Code,
-			Size:      o.Size,
-			Price:     o.Price,
-			CreatedAt: o.CreatedAt,
-		})
+	b.Execute(oid, 0, 0, true)
+}
+
+// Execute apply execution of order to position of order code
+// size zero means remain size of order and price zero means order price
+// order is completed when whole size is executed or done is true, otherwise order is partial
+// execution without price like market order of unknown price is rejected instead of booked with zero price
+func (b *Broker) Execute(oid string, size int64, price float64, done bool) {
+	b.execute(oid, size, price, 0, done)
+}
+
+// execute apply execution with commission which store charged
+// commission zero is calculated with commission rate of broker
+func (b *Broker) execute(oid string, size int64, price, commission float64, done bool) {
+	o, ok := b.getOrder(oid)
+	if !ok || !alive(o) {
+		return
+	}
+	b.Do(b.loadPositions)
+
+	if size == 0 {
+		size = o.Remaining()
+	}
+	if price == 0 {
+		price = o.Price
+	}
+	if price == 0 {
+		b.reject(o, error2.ErrNotExistPrice)
+		return
+	}
+	if commission == 0 {
+		commission = price * float64(size) * b.Commission
+	}
+	date := b.now()
+
+	signed := size
+	if o.OType == order.Sell {
+		signed = -size
+	}
+
+	b.mu.Lock()
+	p, ok := b.positions[o.Code]
+	if !ok {
+		p = &position.Position{Code: o.Code}
+		b.positions[o.Code] = p
+	}
+	before := p.Size
+	p.Update(signed, price, commission, date)
+	trades := b.updateTrade(o.Code, before, signed, price, commission, date)
+	b.mu.Unlock()
+
+	o.Execute(size, price, date)
+	if done {
 		o.Complete()
-		b.eventEngine.BroadCast(o)
+	}
+	b.eventEngine.BroadCast(o)
+	for _, t := range trades {
+		b.eventEngine.BroadCast(t)
+	}
+	b.notifyFund()
+
+	if o.Status() == order.Completed {
+		b.finish(o.UUID, true)
 		return
 	}
+	b.partial(o)
 }
 
-func (b *Broker) GetPosition(code string) []position.Position {
-	b.Do(func() {
-		p := b.Store.Positions()
-		for _, i := range p {
-			b.positions[i.Code] = append(b.positions[i.Code], i)
+// updateTrade apply execution to open trade of code and return copies of changed trades
+// position which is held before broker start has no trade, trade is opened after it become flat or flip
+func (b *Broker) updateTrade(code string, before, size int64, price, commission float64, date time.Time) []*trade.Trade {
+	var changed []*trade.Trade
+
+	t, ok := b.trades[code]
+	switch {
+	case ok:
+		size, commission = t.Update(size, price, commission, date)
+		c := *t
+		changed = append(changed, &c)
+		if t.IsOpen() {
+			return changed
 		}
-	})
+		delete(b.trades, code)
+	case before != 0:
+		after := before + size
+		if after == 0 || (before > 0) == (after > 0) {
+			return nil
+		}
+		commission = commission * math.Abs(float64(after)) / math.Abs(float64(size))
+		size = after
+	}
+
+	if size == 0 {
+		return changed
+	}
+	t = trade.New(code, size, price, commission, date)
+	b.trades[code] = t
+	c := *t
+	return append(changed, &c)
+}
+
+func (b *Broker) getOrder(uid string) (*order.Order, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	o, ok := b.orders[uid]
+	return o, ok
+}
+
+// loadPositions initialize position ledger with positions of store
+// simulator start with empty position, so it is skipped
+func (b *Broker) loadPositions() {
+	if _, ok := b.Store.(store.Simulator); ok {
+		return
+	}
+
+	positions := b.Store.Positions()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, i := range positions {
+		if p, ok := b.positions[i.Code]; ok {
+			p.Update(i.Size, i.Price, 0, i.CreatedAt)
+			continue
+		}
+		p := i
+		b.positions[i.Code] = &p
+	}
+}
+
+// GetPosition return net position of code, zero size position if it does not exist
+func (b *Broker) GetPosition(code string) position.Position {
+	b.Do(b.loadPositions)
 
+	b.mu.Lock()
+	defer b.mu.Unlock()
 	if p, ok := b.positions[code]; ok {
-		return p
+		return *p
 	}
-	return nil
+	return position.Position{Code: code}
+}
+
+// Portfolio return every position which broker has ever held in order of code
+func (b *Broker) Portfolio() []position.Position {
+	b.Do(b.loadPositions)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	portfolio := make([]position.Position, 0, len(b.positions))
+	for _, p := range b.positions {
+		portfolio = append(portfolio, *p)
+	}
+	sort.Slice(portfolio, func(i, j int) bool {
+		return portfolio[i].Code < portfolio[j].Code
+	})
+	return portfolio
+}
+
+// now is time of latest market data, or current time if there is no data yet
+func (b *Broker) now() time.Time {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.date.IsZero() {
+		return time.Now()
+	}
+	return b.date
 }
 
 func (b *Broker) GetCash() int64 {
@@ -141,15 +335,125 @@ func (b *Broker) SetEventBroadCaster(e event.Broadcaster) {
 	b.eventEngine = e
 }
 
+// NextCandle feed market data to broker and positions are marked with candle close
+// stop orders touched by candle are sent to store first, and if store is simulator,
+// pending orders are executed with same candle and its events are applied immediately
+// candle is closed bar, so fund of account is broadcast at the end of candle with date of candle
+func (b *Broker) NextCandle(candle container.Candle) {
+	b.next(candle)
+	b.CloseBar(candle, candle.Date)
+}
+
+// next execute orders with market data
+func (b *Broker) next(candle container.Candle) {
+	b.mu.Lock()
+	if candle.Date.After(b.date) {
+		b.date = candle.Date
+	}
+	b.mu.Unlock()
+
+	sim, simulated := b.Store.(store.Simulator)
+	for _, s := range b.triggerStops(candle) {
+		if err := b.Store.Order(s.child(simulated)); err != nil {
+			b.reject(s.Order, err)
+		}
+	}
+
+	if simulated {
+		for _, evt := range sim.Next(candle) {
+			b.Listen(evt)
+		}
+	}
+
+	b.mu.Lock()
+	if p, ok := b.positions[candle.Code]; ok {
+		p.Mark(candle.Close)
+	}
+	b.mu.Unlock()
+}
+
+// CloseBar is called when bar of finest level of code is closed
+// position is marked with bar close, bar length of open trade is counted and fund is broadcast
+// in live trading, orders are executed with ticks, so only this is called for closed bar
+// fund is dated with end of bar, which is after executions in the bar
+func (b *Broker) CloseBar(candle container.Candle, end time.Time) {
+	b.mu.Lock()
+	if p, ok := b.positions[candle.Code]; ok {
+		p.Mark(candle.Close)
+	}
+	if t, ok := b.trades[candle.Code]; ok {
+		t.BarLen++
+	}
+	b.mu.Unlock()
+
+	// live bar is closed by tick of next period, so fund is dated with bar instead of latest tick
+	f := b.Fund()
+	f.Date = end
+	b.eventEngine.BroadCast(&f)
+}
+
+// triggerStops remove stop orders which are triggered by candle
+// and trailing stop price of remain orders follow the candle
+func (b *Broker) triggerStops(candle container.Candle) []*stopOrder {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var triggered []*stopOrder
+	remain := b.stops[:0]
+	for _, s := range b.stops {
+		if s.Code != candle.Code {
+			remain = append(remain, s)
+			continue
+		}
+
+		if s.triggered(candle) {
+			triggered = append(triggered, s)
+			continue
+		}
+		s.trail(candle)
+		remain = append(remain, s)
+	}
+	b.stops = remain
+	return triggered
+}
+
+func (b *Broker) removeStop(uid string) (*stopOrder, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for k, s := range b.stops {
+		if s.UUID == uid {
+			b.stops = append(b.stops[:k], b.stops[k+1:]...)
+			return s, true
+		}
+	}
+	return nil, false
+}
+
+// NextTick feed tick to broker as candle of single price
+func (b *Broker) NextTick(tick container.Tick) {
+	b.next(container.Candle{
+		Code:   tick.Code,
+		Open:   tick.Price,
+		High:   tick.Price,
+		Low:    tick.Price,
+		Close:  tick.Price,
+		Volume: tick.Volume,
+		Date:   tick.Date,
+	})
+}
+
 func (b *Broker) Listen(e interface{}) {
 	if evt, ok := e.(event.OrderEvent); ok {
 		switch evt.State {
 		case "cancel":
-			b.Cancel(evt.Oid)
+			if o, ok := b.getOrder(evt.Oid); ok && alive(o) {
+				b.canceled(o)
+			}
+		case "partial":
+			b.execute(evt.Oid, evt.Size, evt.Price, evt.Commission, false)
 		case "done":
-			b.Accept(evt.Oid)
+			b.execute(evt.Oid, evt.Size, evt.Price, evt.Commission, true)
 		case "wait":
-			fmt.Println(b.positions)
 			fmt.Println("wait")
 		}
 	}
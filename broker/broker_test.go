@@ -5,10 +5,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/gobenpark/trader/container"
+	"github.com/gobenpark/trader/event"
 	mock_event "github.com/gobenpark/trader/event/mock"
 	"github.com/gobenpark/trader/order"
 	"github.com/gobenpark/trader/position"
 	mock_store "github.com/gobenpark/trader/store/mock"
+	"github.com/gobenpark/trader/store/simulator"
+	"github.com/gobenpark/trader/trade"
 	"github.com/golang/mock/gomock"
 	uuid "github.com/satori/go.uuid"
 	"github.com/stretchr/testify/assert"
@@ -128,15 +132,69 @@ func TestBroker_Accept(t *testing.T) {
 	}
 
 	e.EXPECT().BroadCast(gomock.AssignableToTypeOf(b.orders["test"]))
+	e.EXPECT().BroadCast(gomock.AssignableToTypeOf(&trade.Trade{}))
+	e.EXPECT().BroadCast(gomock.AssignableToTypeOf(&Fund{}))
+	store.EXPECT().Positions()
+	store.EXPECT().Cash()
 	b.Accept("test")
 
-	assert.Len(t, b.positions["code"], 1)
-	assert.Equal(t, b.positions["code"][0].Code, "code")
-	assert.Equal(t, b.positions["code"][0].Price, float64(21))
-	assert.Equal(t, b.positions["code"][0].Size, int64(10))
+	assert.Equal(t, b.positions["code"].Code, "code")
+	assert.Equal(t, b.positions["code"].Price, float64(21))
+	assert.Equal(t, b.positions["code"].Size, int64(10))
 	assert.Equal(t, order.Completed, b.orders["test"].Status())
 }
 
+func TestBroker_ExecuteWithoutPrice(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	e := mock_event.NewMockBroadcaster(ctrl)
+	store := mock_store.NewMockStore(ctrl)
+
+	b := NewBroker()
+	b.SetEventBroadCaster(e)
+	b.Store = store
+
+	o := &order.Order{OType: order.Buy, ExecType: order.Market, Code: "code", UUID: "test", Size: 10}
+	b.orders["test"] = o
+
+	// market order has no price, so fill without price can not be booked
+	e.EXPECT().BroadCast(o)
+	store.EXPECT().Positions()
+	b.Listen(event.OrderEvent{State: "done", Oid: "test", Size: 10})
+
+	assert.Equal(t, order.Rejected, o.Status())
+	assert.Equal(t, int64(0), o.ExecutedSize)
+	assert.Equal(t, int64(0), b.GetPosition("code").Size)
+}
+
+func TestBroker_AcceptSell(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	e := mock_event.NewMockBroadcaster(ctrl)
+	store := mock_store.NewMockStore(ctrl)
+
+	b := NewBroker()
+	b.SetEventBroadCaster(e)
+	b.Store = store
+	b.Commission = 0.01
+
+	b.orders["buy"] = &order.Order{OType: order.Buy, Code: "code", UUID: "buy", Size: 10, Price: 100}
+	b.orders["sell"] = &order.Order{OType: order.Sell, Code: "code", UUID: "sell", Size: 4, Price: 150}
+
+	e.EXPECT().BroadCast(gomock.AssignableToTypeOf(&order.Order{})).Times(2)
+	e.EXPECT().BroadCast(gomock.AssignableToTypeOf(&Fund{})).Times(2)
+	store.EXPECT().Positions().Return([]position.Position{{Code: "code", Size: 10, Price: 50}})
+	store.EXPECT().Cash().Times(2)
+	b.Accept("buy")
+	b.Accept("sell")
+
+	p := b.GetPosition("code")
+	assert.Equal(t, int64(16), p.Size)
+	assert.Equal(t, float64(75), p.Price)
+	assert.Equal(t, float64(300), p.RealizedPnL)
+	assert.Equal(t, float64(16), p.Commission)
+	assert.Equal(t, float64(150), p.MarketPrice)
+	assert.Equal(t, float64(1200), p.UnrealizedPnL)
+}
+
 func TestBroker_Cancel(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	e := mock_event.NewMockBroadcaster(ctrl)
@@ -155,10 +213,17 @@ func TestBroker_Cancel(t *testing.T) {
 		StoreUID: "",
 	}
 	e.EXPECT().BroadCast(input)
+	store.EXPECT().Cancel("test").Return(nil)
 	b.orders["test"] = input
 
 	b.Cancel("test")
 	assert.Equal(t, order.Canceled, input.Status())
+
+	t.Run("completed order is not canceled", func(t *testing.T) {
+		input.Complete()
+		b.Cancel("test")
+		assert.Equal(t, order.Completed, input.Status())
+	})
 }
 
 func TestBroker_GetCash(t *testing.T) {
@@ -178,17 +243,73 @@ func TestBroker_GetPosition(t *testing.T) {
 	store := mock_store.NewMockStore(ctrl)
 	b.Store = store
 
-	b.positions["code"] = append(b.positions["code"], position.Position{
+	b.positions["code"] = &position.Position{
 		Code:      "code",
 		Size:      1,
 		Price:     1,
 		CreatedAt: time.Time{},
-	})
+	}
 
 	store.EXPECT().Positions()
 
 	p := b.GetPosition("code")
-	assert.Len(t, p, 1)
+	assert.Equal(t, int64(1), p.Size)
+	assert.Equal(t, int64(0), b.GetPosition("none").Size)
+}
+
+func TestBroker_Portfolio(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	e := mock_event.NewMockBroadcaster(ctrl)
+	e.EXPECT().BroadCast(gomock.Any()).AnyTimes()
+
+	b := NewBroker()
+	b.SetEventBroadCaster(e)
+	b.Store = simulator.NewStore(nil, 100000, 0)
+
+	b.Buy("b", 10, 0, order.Market)
+	b.Buy("a", 5, 100, order.Limit)
+	b.NextCandle(container.Candle{Code: "a", Open: 100, High: 100, Low: 100, Close: 110})
+	b.NextCandle(container.Candle{Code: "b", Open: 200, High: 200, Low: 200, Close: 180})
+
+	p := b.Portfolio()
+	assert.Len(t, p, 2)
+	assert.Equal(t, "a", p[0].Code)
+	assert.Equal(t, float64(50), p[0].UnrealizedPnL)
+	assert.Equal(t, "b", p[1].Code)
+	assert.Equal(t, float64(200), p[1].Price)
+	assert.Equal(t, float64(180), p[1].MarketPrice)
+}
+
+func TestBroker_PartialExecute(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	e := mock_event.NewMockBroadcaster(ctrl)
+
+	b := NewBroker()
+	b.SetEventBroadCaster(e)
+	b.Store = simulator.NewStore(nil, 100000, 0, simulator.WithVolumeLimit(0.5))
+
+	var status []order.Status
+	e.EXPECT().BroadCast(gomock.Any()).Do(func(evt interface{}) {
+		if o, ok := evt.(*order.Order); ok {
+			status = append(status, o.Status())
+		}
+	}).AnyTimes()
+
+	uid := b.Buy("code", 10, 0, order.Market)
+	b.NextCandle(container.Candle{Code: "code", Open: 100, High: 100, Low: 100, Close: 100, Volume: 8})
+	assert.Equal(t, int64(4), b.GetPosition("code").Size)
+	assert.Equal(t, order.Partial, b.orders[uid].Status())
+
+	b.NextCandle(container.Candle{Code: "code", Open: 110, High: 110, Low: 110, Close: 110, Volume: 20})
+	p := b.GetPosition("code")
+	assert.Equal(t, int64(10), p.Size)
+	assert.Equal(t, float64(106), p.Price)
+
+	o := b.orders[uid]
+	assert.Equal(t, order.Completed, o.Status())
+	assert.Equal(t, float64(106), o.ExecutedPrice)
+	assert.Len(t, o.Fills, 2)
+	assert.Equal(t, []order.Status{order.Submitted, order.Partial, order.Completed}, status)
 }
 
 func TestBroker_SetCash(t *testing.T) {
@@ -197,3 +318,394 @@ func TestBroker_SetCash(t *testing.T) {
 	b.SetCash(20)
 	assert.Equal(t, int64(20), b.Cash)
 }
+
+func TestBroker_NextCandle(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	e := mock_event.NewMockBroadcaster(ctrl)
+	store := mock_store.NewMockSimulator(ctrl)
+
+	b := NewBroker()
+	b.SetEventBroadCaster(e)
+	b.Store = store
+	b.orders["test"] = &order.Order{
+		Code:  "code",
+		UUID:  "test",
+		Size:  10,
+		Price: 21,
+	}
+
+	candle := container.Candle{Code: "code", Open: 21, High: 21, Low: 21, Close: 21}
+	store.EXPECT().Next(candle).Return([]event.OrderEvent{{State: "done", Oid: "test"}})
+	e.EXPECT().BroadCast(b.orders["test"])
+	e.EXPECT().BroadCast(gomock.AssignableToTypeOf(&trade.Trade{}))
+	e.EXPECT().BroadCast(gomock.AssignableToTypeOf(&Fund{})).Times(2)
+	store.EXPECT().Cash().Return(int64(790)).Times(2)
+
+	b.NextCandle(candle)
+	assert.Equal(t, order.Completed, b.orders["test"].Status())
+	assert.Equal(t, int64(10), b.positions["code"].Size)
+}
+
+func TestBroker_StopOrder(t *testing.T) {
+	candle := func(high, low float64) container.Candle {
+		return container.Candle{Code: "code", Open: high, High: high, Low: low, Close: low}
+	}
+
+	tests := []struct {
+		name    string
+		submit  func(b *Broker) string
+		candles []container.Candle
+		exec    order.ExecType
+		price   float64
+	}{
+		{
+			"stop sell become market order",
+			func(b *Broker) string {
+				return b.Sell("code", 10, 95, order.Stop)
+			},
+			[]container.Candle{candle(100, 96), candle(97, 94)},
+			order.Market,
+			95,
+		},
+		{
+			"stop buy become market order",
+			func(b *Broker) string {
+				return b.Buy("code", 10, 105, order.Stop)
+			},
+			[]container.Candle{candle(104, 100), candle(106, 101)},
+			order.Market,
+			105,
+		},
+		{
+			"stop limit become limit order",
+			func(b *Broker) string {
+				return b.Sell("code", 10, 95, order.StopLimit, order.WithPriceLimit(93))
+			},
+			[]container.Candle{candle(100, 96), candle(97, 94)},
+			order.Limit,
+			93,
+		},
+		{
+			"trailing amount follow high price",
+			func(b *Broker) string {
+				return b.Sell("code", 10, 0, order.StopTrail, order.WithTrailAmount(5))
+			},
+			[]container.Candle{candle(100, 96), candle(110, 106), candle(108, 106), candle(107, 104)},
+			order.Market,
+			105,
+		},
+		{
+			"trailing percent follow low price",
+			func(b *Broker) string {
+				return b.Buy("code", 10, 0, order.StopTrailLimit, order.WithTrailPercent(0.1), order.WithPriceLimit(120))
+			},
+			[]container.Candle{candle(110, 100), candle(100, 90), candle(98, 92), candle(100, 95)},
+			order.Limit,
+			120,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			e := mock_event.NewMockBroadcaster(ctrl)
+			store := mock_store.NewMockStore(ctrl)
+
+			b := NewBroker()
+			b.SetEventBroadCaster(e)
+			b.Store = store
+
+			e.EXPECT().BroadCast(gomock.AssignableToTypeOf(&order.Order{})).Times(1)
+			e.EXPECT().BroadCast(gomock.AssignableToTypeOf(&Fund{})).Times(len(test.candles))
+			store.EXPECT().Positions().Times(1)
+			store.EXPECT().Cash().Times(len(test.candles))
+			uid := test.submit(b)
+			assert.Len(t, b.stops, 1)
+
+			var child *order.Order
+			store.EXPECT().Order(gomock.Any()).DoAndReturn(func(o *order.Order) error {
+				child = o
+				return nil
+			}).Times(1)
+
+			for _, c := range test.candles {
+				assert.Nil(t, child)
+				b.NextCandle(c)
+			}
+
+			require.NotNil(t, child)
+			assert.Equal(t, uid, child.UUID)
+			assert.Equal(t, test.exec, child.ExecType)
+			assert.Equal(t, test.price, child.Price)
+			assert.Len(t, b.stops, 0)
+		})
+	}
+}
+
+func TestBroker_CancelStopOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	e := mock_event.NewMockBroadcaster(ctrl)
+	store := mock_store.NewMockStore(ctrl)
+
+	b := NewBroker()
+	b.SetEventBroadCaster(e)
+	b.Store = store
+
+	e.EXPECT().BroadCast(gomock.AssignableToTypeOf(&order.Order{})).Times(2)
+	e.EXPECT().BroadCast(gomock.AssignableToTypeOf(&Fund{})).Times(1)
+	store.EXPECT().Positions().Times(1)
+	store.EXPECT().Cash().Times(1)
+	uid := b.Sell("code", 10, 95, order.Stop)
+	b.Cancel(uid)
+
+	assert.Len(t, b.stops, 0)
+	assert.Equal(t, order.Canceled, b.orders[uid].Status())
+	b.NextCandle(container.Candle{Code: "code", Open: 90, High: 90, Low: 90, Close: 90})
+}
+
+func TestBroker_BuyBracket(t *testing.T) {
+	candle := func(open, high, low, close float64) container.Candle {
+		return container.Candle{Code: "code", Open: open, High: high, Low: low, Close: close, Date: time.Now()}
+	}
+
+	tests := []struct {
+		name    string
+		candles []container.Candle
+		status  [3]order.Status
+	}{
+		{
+			"take profit",
+			[]container.Candle{candle(100, 100, 100, 100), candle(100, 115, 99, 112)},
+			[3]order.Status{order.Completed, order.Canceled, order.Completed},
+		},
+		{
+			"stop loss",
+			[]container.Candle{candle(100, 100, 100, 100), candle(100, 101, 85, 88), candle(88, 88, 80, 85)},
+			[3]order.Status{order.Completed, order.Completed, order.Canceled},
+		},
+		{
+			"children wait parent",
+			[]container.Candle{},
+			[3]order.Status{order.Submitted, 0, 0},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			e := mock_event.NewMockBroadcaster(ctrl)
+			e.EXPECT().BroadCast(gomock.Any()).AnyTimes()
+
+			b := NewBroker()
+			b.SetEventBroadCaster(e)
+			b.Store = simulator.NewStore(nil, 100000, 0)
+
+			parent, stop, target := b.BuyBracket("code", 10, 0, 90, 110)
+			for _, c := range test.candles {
+				b.NextCandle(c)
+			}
+
+			for k, uid := range []string{parent, stop, target} {
+				assert.Equal(t, test.status[k], b.orders[uid].Status())
+			}
+			assert.Equal(t, parent, b.orders[stop].Parent)
+			assert.Equal(t, parent, b.orders[target].Parent)
+		})
+	}
+}
+
+func TestBroker_CancelBracket(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	e := mock_event.NewMockBroadcaster(ctrl)
+	e.EXPECT().BroadCast(gomock.Any()).AnyTimes()
+
+	b := NewBroker()
+	b.SetEventBroadCaster(e)
+	b.Store = simulator.NewStore(nil, 100000, 0)
+
+	parent, stop, target := b.SellBracket("code", 10, 120, 130, 100)
+	b.Cancel(parent)
+
+	for _, uid := range []string{parent, stop, target} {
+		assert.Equal(t, order.Canceled, b.orders[uid].Status())
+	}
+	assert.Len(t, b.children, 0)
+	assert.Len(t, b.oco, 0)
+}
+
+func TestBroker_PartialBracket(t *testing.T) {
+	candle := func(high, low, volume float64) container.Candle {
+		return container.Candle{Code: "code", Open: 100, High: high, Low: low, Close: 100, Volume: volume, Date: time.Now()}
+	}
+
+	t.Run("partial take profit resize stop loss", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		e := mock_event.NewMockBroadcaster(ctrl)
+		e.EXPECT().BroadCast(gomock.Any()).AnyTimes()
+
+		b := NewBroker()
+		b.SetEventBroadCaster(e)
+		b.Store = simulator.NewStore(nil, 100000, 0, simulator.WithVolumeLimit(0.5))
+
+		parent, stop, target := b.BuyBracket("code", 10, 0, 90, 110)
+		b.NextCandle(candle(100, 100, 100))
+		require.Equal(t, order.Completed, b.orders[parent].Status())
+
+		// only 4 of take profit is filled with volume limit
+		b.NextCandle(candle(115, 99, 8))
+		assert.Equal(t, order.Partial, b.orders[target].Status())
+		assert.Equal(t, int64(6), b.orders[stop].Remaining())
+
+		// stop loss close remain position without flip
+		b.NextCandle(candle(100, 85, 100))
+		assert.Equal(t, order.Completed, b.orders[stop].Status())
+		assert.Equal(t, order.Canceled, b.orders[target].Status())
+		assert.Equal(t, int64(0), b.GetPosition("code").Size)
+	})
+
+	t.Run("canceled partial parent submit children with executed size", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		e := mock_event.NewMockBroadcaster(ctrl)
+		e.EXPECT().BroadCast(gomock.Any()).AnyTimes()
+
+		b := NewBroker()
+		b.SetEventBroadCaster(e)
+		b.Store = simulator.NewStore(nil, 100000, 0, simulator.WithVolumeLimit(0.5))
+
+		parent, stop, target := b.BuyBracket("code", 10, 100, 90, 110)
+		b.NextCandle(candle(101, 99, 8))
+		require.Equal(t, order.Partial, b.orders[parent].Status())
+
+		b.Cancel(parent)
+		assert.Equal(t, order.Canceled, b.orders[parent].Status())
+		for _, uid := range []string{stop, target} {
+			assert.Equal(t, order.Submitted, b.orders[uid].Status())
+			assert.Equal(t, int64(4), b.orders[uid].Size)
+		}
+
+		b.NextCandle(candle(100, 85, 100))
+		assert.Equal(t, order.Completed, b.orders[stop].Status())
+		assert.Equal(t, order.Canceled, b.orders[target].Status())
+		assert.Equal(t, int64(0), b.GetPosition("code").Size)
+	})
+}
+
+func TestBroker_OCO(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	e := mock_event.NewMockBroadcaster(ctrl)
+	e.EXPECT().BroadCast(gomock.Any()).AnyTimes()
+
+	b := NewBroker()
+	b.SetEventBroadCaster(e)
+	b.Store = simulator.NewStore(nil, 100000, 0)
+
+	low := b.Buy("code", 10, 90, order.Limit)
+	high := b.Buy("code", 10, 110, order.Stop)
+	b.OCO(low, high)
+
+	b.NextCandle(container.Candle{Code: "code", Open: 100, High: 101, Low: 89, Close: 95})
+	assert.Equal(t, order.Completed, b.orders[low].Status())
+	assert.Equal(t, order.Canceled, b.orders[high].Status())
+	assert.Len(t, b.stops, 0)
+}
+
+func TestBroker_Trade(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	e := mock_event.NewMockBroadcaster(ctrl)
+
+	b := NewBroker()
+	b.SetEventBroadCaster(e)
+	b.Store = simulator.NewStore(nil, 100000, 0)
+
+	var trades []*trade.Trade
+	e.EXPECT().BroadCast(gomock.Any()).Do(func(evt interface{}) {
+		if t, ok := evt.(*trade.Trade); ok {
+			trades = append(trades, t)
+		}
+	}).AnyTimes()
+
+	date := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	candle := func(price float64, day int) container.Candle {
+		return container.Candle{Code: "code", Open: price, High: price, Low: price, Close: price, Date: date.AddDate(0, 0, day)}
+	}
+
+	b.Buy("code", 10, 0, order.Market)
+	b.NextCandle(candle(100, 0))
+	b.Buy("code", 10, 0, order.Market)
+	b.NextCandle(candle(110, 1))
+	b.NextCandle(candle(115, 2))
+	b.Sell("code", 30, 0, order.Market)
+	b.NextCandle(candle(120, 3))
+
+	require.Len(t, trades, 4)
+	assert.Equal(t, trade.Open, trades[0].Status)
+	assert.Equal(t, int64(10), trades[0].Size)
+
+	assert.Equal(t, trades[0].ID, trades[1].ID)
+	assert.Equal(t, int64(20), trades[1].Size)
+	assert.Equal(t, float64(105), trades[1].EntryPrice)
+
+	closed := trades[2]
+	assert.Equal(t, trades[0].ID, closed.ID)
+	assert.Equal(t, trade.Closed, closed.Status)
+	assert.Equal(t, float64(300), closed.PnL)
+	assert.Equal(t, float64(120), closed.ExitPrice)
+	assert.Equal(t, 3, closed.BarLen)
+	assert.Equal(t, date, closed.OpenedAt)
+	assert.Equal(t, date.AddDate(0, 0, 3), closed.ClosedAt)
+
+	assert.NotEqual(t, closed.ID, trades[3].ID)
+	assert.Equal(t, trade.Open, trades[3].Status)
+	assert.Equal(t, int64(-10), trades[3].Size)
+	assert.False(t, trades[3].Long)
+}
+
+func TestBroker_Fund(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	e := mock_event.NewMockBroadcaster(ctrl)
+
+	b := NewBroker()
+	b.SetEventBroadCaster(e)
+	b.Store = simulator.NewStore(nil, 1000, 0)
+
+	var funds []*Fund
+	e.EXPECT().BroadCast(gomock.Any()).Do(func(evt interface{}) {
+		if f, ok := evt.(*Fund); ok {
+			funds = append(funds, f)
+		}
+	}).AnyTimes()
+
+	b.Buy("code", 10, 0, order.Market)
+	b.NextCandle(container.Candle{Code: "code", Open: 50, High: 60, Low: 50, Close: 60})
+
+	require.Len(t, funds, 2)
+	assert.Equal(t, float64(1000), funds[0].Value)
+	assert.Equal(t, float64(100), funds[0].FundValue)
+	assert.Equal(t, float64(10), funds[0].Shares)
+	assert.Equal(t, float64(500), funds[1].Cash)
+	assert.Equal(t, float64(1100), funds[1].Value)
+	assert.Equal(t, float64(110), funds[1].FundValue)
+	require.Len(t, funds[1].Positions, 1)
+	assert.Equal(t, float64(600), funds[1].Positions[0].Value())
+	assert.Equal(t, float64(1100), b.Value())
+}
+
+func TestBroker_StoreCommission(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	e := mock_event.NewMockBroadcaster(ctrl)
+	e.EXPECT().BroadCast(gomock.Any()).AnyTimes()
+
+	// commission rate is set only in simulator, broker use commission of its event
+	b := NewBroker()
+	b.SetEventBroadCaster(e)
+	b.Store = simulator.NewStore(nil, 10000, 0.01)
+
+	b.Buy("code", 10, 0, order.Market)
+	b.NextCandle(container.Candle{Code: "code", Open: 100, High: 100, Low: 100, Close: 100})
+
+	p := b.GetPosition("code")
+	assert.Equal(t, int64(10), p.Size)
+	assert.Equal(t, float64(10), p.Commission)
+	require.Len(t, b.trades, 1)
+	assert.Equal(t, float64(10), b.trades["code"].Commission)
+}
@@ -0,0 +1,84 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package broker
+
+import (
+	"sort"
+	"time"
+
+	"github.com/gobenpark/trader/position"
+)
+
+// fundStartValue is fund value of one share when broker start
+const fundStartValue = 100
+
+// Fund is snapshot of account, Value is cash plus market value of positions
+// Shares is decided with first value so that FundValue start from 100
+// it makes performance comparable even when cash is changed
+type Fund struct {
+	Date      time.Time `json:"date"`
+	Cash      float64   `json:"cash"`
+	Value     float64   `json:"value"`
+	FundValue float64   `json:"fundValue"`
+	Shares    float64   `json:"shares"`
+	// Positions open positions in order of code
+	Positions []position.Position `json:"positions"`
+	// Execution is true for snapshot after execution, otherwise it is snapshot at close of bar
+	Execution bool `json:"execution"`
+}
+
+// Value is cash plus market value of every position
+func (b *Broker) Value() float64 {
+	return b.Fund().Value
+}
+
+// Fund evaluate account with latest market price of positions
+func (b *Broker) Fund() Fund {
+	b.Do(b.loadPositions)
+	cash := float64(b.Store.Cash())
+	date := b.now()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	f := Fund{Date: date, Cash: cash, Value: cash}
+	for _, p := range b.positions {
+		if p.Size == 0 {
+			continue
+		}
+		f.Value += p.Value()
+		f.Positions = append(f.Positions, *p)
+	}
+	sort.Slice(f.Positions, func(i, j int) bool {
+		return f.Positions[i].Code < f.Positions[j].Code
+	})
+
+	if b.shares == 0 && f.Value > 0 {
+		b.shares = f.Value / fundStartValue
+	}
+	f.Shares = b.shares
+	f.FundValue = fundStartValue
+	if b.shares != 0 {
+		f.FundValue = f.Value / b.shares
+	}
+	return f
+}
+
+// notifyFund broadcast fund snapshot after execution
+func (b *Broker) notifyFund() {
+	f := b.Fund()
+	f.Execution = true
+	b.eventEngine.BroadCast(&f)
+}
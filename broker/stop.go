@@ -0,0 +1,107 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package broker
+
+import (
+	"github.com/gobenpark/trader/container"
+	"github.com/gobenpark/trader/order"
+)
+
+// stopOrder is stop order which broker keep until market price touch stop price
+// so exchange which does not support stop order can be used
+type stopOrder struct {
+	*order.Order
+	// trigger current stop price, trailing order without price has zero until first candle
+	trigger float64
+	// best highest price for sell or lowest price for buy since order is submitted
+	best float64
+}
+
+func newStopOrder(o *order.Order) *stopOrder {
+	return &stopOrder{Order: o, trigger: o.Price}
+}
+
+// trail move stop price of trailing order with candle
+// stop price only move toward market, sell stop go up and buy stop go down
+func (s *stopOrder) trail(candle container.Candle) {
+	if !s.ExecType.IsTrail() {
+		return
+	}
+
+	switch s.OType {
+	case order.Sell:
+		if s.best == 0 || candle.High > s.best {
+			s.best = candle.High
+		}
+		stop := s.best - s.TrailAmount
+		if s.TrailPercent != 0 {
+			stop = s.best * (1 - s.TrailPercent)
+		}
+		if stop > s.trigger {
+			s.trigger = stop
+		}
+	case order.Buy:
+		if s.best == 0 || candle.Low < s.best {
+			s.best = candle.Low
+		}
+		stop := s.best + s.TrailAmount
+		if s.TrailPercent != 0 {
+			stop = s.best * (1 + s.TrailPercent)
+		}
+		if s.trigger == 0 || stop < s.trigger {
+			s.trigger = stop
+		}
+	}
+}
+
+// triggered is true if candle touch stop price
+func (s *stopOrder) triggered(candle container.Candle) bool {
+	if s.trigger == 0 {
+		return false
+	}
+
+	if s.OType == order.Buy {
+		return candle.High >= s.trigger
+	}
+	return candle.Low <= s.trigger
+}
+
+// child is order which is sent to store when stop price is triggered
+// it has same uuid with stop order, so store event of child complete stop order
+// Stop, StopTrail become market order and StopLimit, StopTrailLimit become limit order
+// with simulated store, Stop, StopTrail become stop order of trigger price, so it is filled on triggering candle
+func (s *stopOrder) child(simulated bool) *order.Order {
+	c := &order.Order{
+		OType:     s.OType,
+		ExecType:  order.Market,
+		Code:      s.Code,
+		UUID:      s.UUID,
+		Size:      s.Size,
+		Price:     s.trigger,
+		CreatedAt: s.CreatedAt,
+	}
+
+	switch {
+	case s.ExecType == order.StopLimit || s.ExecType == order.StopTrailLimit:
+		c.ExecType = order.Limit
+		if s.PriceLimit != 0 {
+			c.Price = s.PriceLimit
+		}
+	case simulated:
+		c.ExecType = order.Stop
+	}
+	return c
+}
@@ -0,0 +1,279 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package cerebro
+
+import (
+	"math"
+	"time"
+
+	"github.com/gobenpark/trader/container"
+)
+
+// barBuilder make candles of one bar spec from ticks
+type barBuilder interface {
+	// add apply tick and return candles which are closed by it
+	add(t container.Tick) []container.Candle
+	// flush return current unfinished candle
+	flush() []container.Candle
+	// wait is duration until candle should be closed without tick
+	wait() (time.Duration, bool)
+	// expire close candles which period is finished
+	expire() []container.Candle
+}
+
+// runBars build candles of every builder from ticks until tick channel is closed
+// onTick is called with every tick before builders, and emit is called with closed candles of each builder
+// so candles closed at same time are handled together, unfinished candles are flushed at last
+func runBars(tick <-chan container.Tick, builders []barBuilder, onTick func(t container.Tick), emit func(closed [][]container.Candle)) {
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	for {
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		var timeout <-chan time.Time
+		if wait, ok := waitBars(builders); ok {
+			timer.Reset(wait)
+			timeout = timer.C
+		}
+
+		closed := make([][]container.Candle, len(builders))
+		select {
+		case t, ok := <-tick:
+			if !ok {
+				for i, b := range builders {
+					closed[i] = b.flush()
+				}
+				emitBars(closed, emit)
+				return
+			}
+			if onTick != nil {
+				onTick(t)
+			}
+			for i, b := range builders {
+				closed[i] = b.add(t)
+			}
+		case <-timeout:
+			for i, b := range builders {
+				closed[i] = b.expire()
+			}
+		}
+		emitBars(closed, emit)
+	}
+}
+
+// waitBars is shortest wait of builders
+func waitBars(builders []barBuilder) (time.Duration, bool) {
+	var min time.Duration
+	found := false
+	for _, b := range builders {
+		if wait, ok := b.wait(); ok && (!found || wait < min) {
+			min = wait
+			found = true
+		}
+	}
+	return min, found
+}
+
+func emitBars(closed [][]container.Candle, emit func(closed [][]container.Candle)) {
+	for _, candles := range closed {
+		if len(candles) != 0 {
+			emit(closed)
+			return
+		}
+	}
+}
+
+// BarCompression make candles of spec from ticks, candle is dated by its last tick except time bar of right edge
+// unfinished candle is flushed when tick channel is closed, except renko brick
+// ticks are drained without candle if spec is not valid
+func BarCompression(tick <-chan container.Tick, spec container.BarSpec, opts ...CompressionOption) <-chan container.Candle {
+	ch := make(chan container.Candle, 1)
+	b := newBarBuilder(spec, false, opts...)
+	if b == nil {
+		GetLogger().Warningf("%s bar is not valid", spec)
+		go func() {
+			defer close(ch)
+			for range tick {
+			}
+		}()
+		return ch
+	}
+	go func() {
+		defer close(ch)
+		runBars(tick, []barBuilder{b}, nil, func(closed [][]container.Candle) {
+			for _, candle := range closed[0] {
+				ch <- candle
+			}
+		})
+	}()
+	return ch
+}
+
+// newBarBuilder make builder of spec, candle of time bar is dated by left edge if leftEdge is true
+// nil if spec is not valid
+func newBarBuilder(spec container.BarSpec, leftEdge bool, opts ...CompressionOption) barBuilder {
+	if !spec.Valid() {
+		return nil
+	}
+	switch spec.Type {
+	case container.BarTime:
+		return newCompressor(spec.Level, leftEdge, opts...)
+	case container.BarTick:
+		return &thresholdBar{size: spec.Size, measure: func(container.Tick) float64 { return 1 }}
+	case container.BarVolume:
+		return &thresholdBar{size: spec.Size, measure: func(t container.Tick) float64 { return t.Volume }}
+	case container.BarDollar:
+		return &thresholdBar{size: spec.Size, measure: func(t container.Tick) float64 { return t.Price * t.Volume }}
+	case container.BarRenko:
+		return &renkoBar{brick: spec.Size}
+	case container.BarRange:
+		return &rangeBar{size: spec.Size}
+	}
+	return nil
+}
+
+// candleBuffer is candle being built from ticks
+type candleBuffer struct {
+	bar *container.Candle
+}
+
+func (c *candleBuffer) apply(t container.Tick) {
+	if c.bar == nil {
+		c.bar = &container.Candle{Code: t.Code, Open: t.Price, High: t.Price, Low: t.Price}
+	}
+	c.bar.Close = t.Price
+	c.bar.Volume += t.Volume
+	c.bar.Date = t.Date
+	c.bar.High = math.Max(c.bar.High, t.Price)
+	c.bar.Low = math.Min(c.bar.Low, t.Price)
+}
+
+func (c *candleBuffer) close() []container.Candle {
+	if c.bar == nil {
+		return nil
+	}
+	bar := *c.bar
+	c.bar = nil
+	return []container.Candle{bar}
+}
+
+func (c *candleBuffer) flush() []container.Candle {
+	return c.close()
+}
+
+func (c *candleBuffer) wait() (time.Duration, bool) {
+	return 0, false
+}
+
+func (c *candleBuffer) expire() []container.Candle {
+	return nil
+}
+
+// thresholdBar close candle when sum of measure of ticks reaches size
+// tick crossing size is included in closing candle
+type thresholdBar struct {
+	candleBuffer
+	size    float64
+	measure func(t container.Tick) float64
+	sum     float64
+}
+
+func (b *thresholdBar) add(t container.Tick) []container.Candle {
+	b.apply(t)
+	b.sum += b.measure(t)
+	if b.sum < b.size {
+		return nil
+	}
+	b.sum = 0
+	return b.close()
+}
+
+// rangeBar close candle when high-low reaches size
+// tick reaching size is included in closing candle
+type rangeBar struct {
+	candleBuffer
+	size float64
+}
+
+func (b *rangeBar) add(t container.Tick) []container.Candle {
+	b.apply(t)
+	if b.bar.High-b.bar.Low < b.size {
+		return nil
+	}
+	return b.close()
+}
+
+// renkoBar make bricks of size whenever price moves brick from close of previous brick
+// one tick can make several bricks, volume is given to first brick
+type renkoBar struct {
+	brick  float64
+	base   float64
+	init   bool
+	volume float64
+}
+
+func (b *renkoBar) add(t container.Tick) []container.Candle {
+	if b.brick <= 0 {
+		return nil
+	}
+	if !b.init {
+		b.base = t.Price
+		b.init = true
+	}
+	b.volume += t.Volume
+
+	var bricks []container.Candle
+	for {
+		var next float64
+		switch {
+		case t.Price >= b.base+b.brick:
+			next = b.base + b.brick
+		case t.Price <= b.base-b.brick:
+			next = b.base - b.brick
+		default:
+			return bricks
+		}
+
+		bricks = append(bricks, container.Candle{
+			Code:   t.Code,
+			Open:   b.base,
+			High:   math.Max(b.base, next),
+			Low:    math.Min(b.base, next),
+			Close:  next,
+			Volume: b.volume,
+			Date:   t.Date,
+		})
+		b.base = next
+		b.volume = 0
+	}
+}
+
+func (b *renkoBar) flush() []container.Candle {
+	return nil
+}
+
+func (b *renkoBar) wait() (time.Duration, bool) {
+	return 0, false
+}
+
+func (b *renkoBar) expire() []container.Candle {
+	return nil
+}
@@ -0,0 +1,114 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package cerebro
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gobenpark/trader/container"
+	"github.com/stretchr/testify/assert"
+)
+
+func barTicks(prices []float64, volume float64) []container.Tick {
+	base := time.Date(2021, 3, 12, 0, 0, 0, 0, time.UTC)
+	ticks := make([]container.Tick, len(prices))
+	for i, p := range prices {
+		ticks[i] = container.Tick{Code: "test", Date: base.Add(time.Duration(i) * time.Second), Price: p, Volume: volume}
+	}
+	return ticks
+}
+
+func collectBars(spec container.BarSpec, ticks []container.Tick) []container.Candle {
+	ch := make(chan container.Tick)
+	go func() {
+		defer close(ch)
+		for _, t := range ticks {
+			ch <- t
+		}
+	}()
+
+	var candles []container.Candle
+	for c := range BarCompression(ch, spec) {
+		candles = append(candles, c)
+	}
+	return candles
+}
+
+func TestBarCompression(t *testing.T) {
+	ticks := barTicks([]float64{10, 12, 9, 11, 13, 8, 10}, 10)
+
+	t.Run("tick", func(t *testing.T) {
+		candles := collectBars(container.TickBar(3), ticks)
+		assert.Len(t, candles, 3)
+		assert.Equal(t, container.Candle{Code: "test", Open: 10, High: 12, Low: 9, Close: 9, Volume: 30, Date: ticks[2].Date}, candles[0])
+		assert.Equal(t, container.Candle{Code: "test", Open: 11, High: 13, Low: 8, Close: 8, Volume: 30, Date: ticks[5].Date}, candles[1])
+		// last unfinished candle is flushed
+		assert.Equal(t, container.Candle{Code: "test", Open: 10, High: 10, Low: 10, Close: 10, Volume: 10, Date: ticks[6].Date}, candles[2])
+	})
+
+	t.Run("volume", func(t *testing.T) {
+		candles := collectBars(container.VolumeBar(25), ticks)
+		assert.Len(t, candles, 3)
+		assert.Equal(t, 30.0, candles[0].Volume)
+		assert.Equal(t, ticks[2].Date, candles[0].Date)
+	})
+
+	t.Run("dollar", func(t *testing.T) {
+		candles := collectBars(container.DollarBar(200), ticks)
+		assert.Len(t, candles, 4)
+		assert.Equal(t, container.Candle{Code: "test", Open: 10, High: 12, Low: 10, Close: 12, Volume: 20, Date: ticks[1].Date}, candles[0])
+	})
+
+	t.Run("range", func(t *testing.T) {
+		candles := collectBars(container.RangeBar(3), ticks)
+		assert.Len(t, candles, 3)
+		assert.Equal(t, container.Candle{Code: "test", Open: 10, High: 12, Low: 9, Close: 9, Volume: 30, Date: ticks[2].Date}, candles[0])
+		assert.Equal(t, container.Candle{Code: "test", Open: 11, High: 13, Low: 8, Close: 8, Volume: 30, Date: ticks[5].Date}, candles[1])
+	})
+
+	t.Run("renko", func(t *testing.T) {
+		candles := collectBars(container.RenkoBar(2), barTicks([]float64{10, 11, 12, 16, 13, 11}, 10))
+		assert.Equal(t, []container.Candle{
+			{Code: "test", Open: 10, High: 12, Low: 10, Close: 12, Volume: 30, Date: ticks[2].Date},
+			// one tick can make several bricks
+			{Code: "test", Open: 12, High: 14, Low: 12, Close: 14, Volume: 10, Date: ticks[3].Date},
+			{Code: "test", Open: 14, High: 16, Low: 14, Close: 16, Volume: 0, Date: ticks[3].Date},
+			{Code: "test", Open: 16, High: 16, Low: 14, Close: 14, Volume: 10, Date: ticks[4].Date},
+			{Code: "test", Open: 14, High: 14, Low: 12, Close: 12, Volume: 10, Date: ticks[5].Date},
+		}, candles)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		// ticks are drained, so sender is not blocked
+		assert.Empty(t, collectBars(container.BarSpec{Type: container.BarType(100), Size: 1}, ticks))
+		assert.Empty(t, collectBars(container.VolumeBar(0), ticks))
+	})
+}
+
+func TestWithBar_Invalid(t *testing.T) {
+	c := NewCerebro(
+		WithBar("test", container.BarSpec{Type: container.BarType(100), Size: 1}),
+		WithBar("test", container.TickBar(0)),
+		WithResample("test", 0, false),
+		WithBar("test", container.TickBar(3)),
+		WithResample("test", time.Minute, false),
+	)
+	assert.Len(t, c.compress["test"], 2)
+	for _, comp := range c.compress["test"] {
+		assert.NotNil(t, newBarBuilder(comp.bar, comp.LeftEdge, comp.opts...))
+	}
+}
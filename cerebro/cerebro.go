@@ -19,10 +19,13 @@ import (
 	"context"
 	"os"
 	"os/signal"
+	"sort"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/gobenpark/trader/analysis"
 	"github.com/gobenpark/trader/broker"
 	"github.com/gobenpark/trader/chart"
 	"github.com/gobenpark/trader/container"
@@ -60,12 +63,18 @@ type Cerebro struct {
 	//compress compress info map for codes
 	compress map[string][]CompressInfo
 
+	// live wait compressions of live ticks are finished
+	live sync.WaitGroup
+
 	// containers list of all container
 	containers []container.Container
 
 	//strategy.StrategyEngine embedding property for managing user strategy
 	strategyEngine *strategy.Engine
 
+	// analysisEngine deliver bars and events to analyzers
+	analysisEngine *analysis.Engine
+
 	//log in cerebro global logger
 	Logger Logger `validate:"required"`
 
@@ -78,11 +87,28 @@ type Cerebro struct {
 	// preload bool value, decide use candle history
 	preload bool
 
+	// backtest replay candle history through strategies instead of live data
+	backtest bool
+
+	// clock align candles of every code into steps, candles are delivered one by one if it is nil
+	clock *stepClock
+
+	// historyLevel, historyLeftEdge is level of history loaded from store, finest level of code is used if it is zero
+	historyLevel    time.Duration
+	historyLeftEdge bool
+
+	// from, to range of backtest, zero value is unlimited
+	from time.Time
+	to   time.Time
+
 	// dataCh all data container channel
 	dataCh chan container.Container
 
 	o observer.Observer
 
+	// recorder persist live ticks and compressed candles
+	recorder *observer.Recorder
+
 	chart *chart.TraderChart
 }
 
@@ -95,6 +121,7 @@ func NewCerebro(opts ...Option) *Cerebro {
 		Cancel:         cancel,
 		compress:       make(map[string][]CompressInfo),
 		strategyEngine: &strategy.Engine{},
+		analysisEngine: &analysis.Engine{},
 		order:          make(chan order.Order, 1),
 		dataCh:         make(chan container.Container, 1),
 		eventEngine:    event.NewEventEngine(),
@@ -112,15 +139,25 @@ func NewCerebro(opts ...Option) *Cerebro {
 	return c
 }
 
-func (c *Cerebro) getContainer(code string, level time.Duration) container.Container {
+func (c *Cerebro) getContainer(code string, spec container.BarSpec) container.Container {
 	for k, v := range c.containers {
-		if v.Code() == code && v.Level() == level {
+		if v.Code() == code && v.Bar() == spec {
 			return c.containers[k]
 		}
 	}
 	return nil
 }
 
+// Feed is container of time level of code, nil if it does not exist
+func (c *Cerebro) Feed(code string, level time.Duration) container.Container {
+	return c.getContainer(code, container.TimeBar(level))
+}
+
+// Bar is container of bar spec of code, nil if it does not exist
+func (c *Cerebro) Bar(code string, spec container.BarSpec) container.Container {
+	return c.getContainer(code, spec)
+}
+
 // orderEventRoutine is stream of order state
 // if rise order event then event hub send to subscriber
 func (c *Cerebro) orderEventRoutine() {
@@ -138,29 +175,31 @@ func (c *Cerebro) orderEventRoutine() {
 
 //load initializing data from injected store interface
 func (c *Cerebro) load() error {
+	if c.backtest {
+		return c.loadBacktest()
+	}
+
 	//preload is load history data
 	//gocyclo:ignore
 	if c.preload {
 		for _, code := range c.codes {
-			for _, comp := range c.compress[code] {
-				if err := pkg.Retry(10, func() error {
-					candles, err := c.store.LoadHistory(c.Ctx, code, comp.level)
-					if err != nil {
-						c.Logger.Error(err)
-						return err
-					}
-					con := c.getContainer(code, comp.level)
-					for _, candle := range candles {
-						con.Add(candle)
-					}
-
-					select {
-					case c.chart.Input <- con:
-					case <-c.Ctx.Done():
-					}
-					return nil
-				}); err != nil {
-					return err
+			history, err := c.loadHistory(code)
+			if err != nil {
+				return err
+			}
+
+			for i, comp := range c.compress[code] {
+				if comp.bar.Type != container.BarTime {
+					continue
+				}
+				con := c.getContainer(code, comp.bar)
+				for _, candle := range history[i] {
+					con.Add(candle)
+				}
+
+				select {
+				case c.chart.Input <- con:
+				case <-c.Ctx.Done():
 				}
 			}
 		}
@@ -173,6 +212,10 @@ func (c *Cerebro) load() error {
 			return error2.ErrStoreNotExists
 		}
 
+		if c.clock != nil {
+			go c.clock.run(c.Ctx, c.releaseStep)
+		}
+
 		for _, i := range c.codes {
 			var tick <-chan container.Tick
 			if err := pkg.Retry(10, func() error {
@@ -186,35 +229,363 @@ func (c *Cerebro) load() error {
 				return err
 			}
 
+			var feeds []feed
+			finest := finestLevel(c.compress[i])
 			for _, com := range c.compress[i] {
-				if con := c.getContainer(i, com.level); con != nil {
-					go func(t <-chan container.Tick, con container.Container, level time.Duration, isLeftEdge bool) {
-						com, oth := pkg.Tee(c.Ctx, t)
-
-						go func(ch <-chan container.Tick) {
-							for o := range ch {
-								if c.o != nil {
-									c.o.Next(o)
-								}
-							}
-						}(oth)
-
-						for j := range Compression(com, level, isLeftEdge) {
-							con.Add(j)
-							select {
-							case <-c.Ctx.Done():
-								break
-							default:
-								c.dataCh <- con
-								c.chart.Input <- con
-							}
-						}
-					}(tick, con, com.level, com.LeftEdge)
+				if con := c.getContainer(i, com.bar); con != nil {
+					closeBar := com.bar.Type == container.BarTime && com.level == finest
+					feeds = append(feeds, feed{info: com, con: con, closeBar: closeBar})
+				}
+			}
+			c.live.Add(1)
+			go c.liveFeed(tick, feeds)
+		}
+	}
+
+	return nil
+}
+
+// feed is container of one compress info of code
+type feed struct {
+	info CompressInfo
+	con  container.Container
+	// closeBar is true for finest time level of code, broker is notified when its candle is closed
+	closeBar bool
+}
+
+// marketClock is store which has its own market time like replay store
+// time candles of live feed are closed by it instead of wall clock
+type marketClock interface {
+	Now() time.Time
+}
+
+// liveFeed deliver every tick of code to broker, observer and recorder,
+// and make candles of every level of code from it
+// after cerebro is stopped, unfinished candles are flushed so they are still recorded
+func (c *Cerebro) liveFeed(tick <-chan container.Tick, feeds []feed) {
+	defer c.live.Done()
+
+	builders := make([]barBuilder, len(feeds))
+	for i, f := range feeds {
+		opts := f.info.opts
+		if clock, ok := c.store.(marketClock); ok {
+			opts = append(opts[:len(opts):len(opts)], WithCompressionClock(clock.Now))
+		}
+		builders[i] = newBarBuilder(f.info.bar, f.info.LeftEdge, opts...)
+	}
+
+	runBars(pkg.OrDone(c.Ctx, tick), builders, c.nextTick, func(closed [][]container.Candle) {
+		c.deliver(feeds, closed)
+	})
+}
+
+func (c *Cerebro) nextTick(t container.Tick) {
+	c.broker.NextTick(t)
+	if c.o != nil {
+		c.o.Next(t)
+	}
+	if c.recorder != nil {
+		c.recorder.Next(t)
+	}
+}
+
+// deliver add candles closed at same time to containers and deliver containers to strategies
+// every container is updated before any of them is delivered, and higher level is delivered first
+// broker is notified of closed candle of finest level before strategies get it
+// time candles go to step clock if it is enabled
+func (c *Cerebro) deliver(feeds []feed, closed [][]container.Candle) {
+	var updated []stepBar
+	for i, candles := range closed {
+		for _, candle := range candles {
+			if c.recorder != nil {
+				c.recorder.Candle(feeds[i].info.bar, candle)
+			}
+			feeds[i].con.Add(candle)
+			if feeds[i].closeBar {
+				c.broker.CloseBar(candle, newStepBar(feeds[i], candle).end)
+			}
+		}
+		if len(candles) != 0 {
+			updated = append(updated, newStepBar(feeds[i], candles[len(candles)-1]))
+		}
+	}
+
+	sort.SliceStable(updated, func(i, j int) bool {
+		return updated[i].info.level > updated[j].info.level
+	})
+
+	for _, b := range updated {
+		if c.clock != nil && b.info.bar.Type == container.BarTime {
+			c.clock.add(c.Ctx, b)
+			continue
+		}
+
+		select {
+		case <-c.Ctx.Done():
+			return
+		case c.dataCh <- b.con:
+		}
+		c.next(b.con)
+	}
+}
+
+// next deliver container which is delivered to strategies to analyzers and chart
+func (c *Cerebro) next(con container.Container) {
+	c.analysisEngine.Next(con)
+	select {
+	case c.chart.Input <- con:
+	case <-c.Ctx.Done():
+	}
+}
+
+// registerClock register time container of every code to step clock
+func (c *Cerebro) registerClock() {
+	for _, code := range c.codes {
+		for _, comp := range c.compress[code] {
+			if comp.bar.Type != container.BarTime {
+				continue
+			}
+			if con := c.getContainer(code, comp.bar); con != nil {
+				c.clock.register(feed{info: comp, con: con})
+			}
+		}
+	}
+	c.strategyEngine.Steps = make(chan *strategy.Step, 1)
+}
+
+// releaseStep deliver step of live candles to strategies
+func (c *Cerebro) releaseStep(step *strategy.Step) {
+	select {
+	case <-c.Ctx.Done():
+		return
+	case c.strategyEngine.Steps <- step:
+	}
+	for _, con := range step.Containers {
+		c.next(con)
+	}
+}
+
+// loadHistory load candles of every time level of code in order of compress info
+// candles of base level are loaded from store once and coarser levels are resampled from them,
+// so store needs to provide only one resolution
+// base level is finest level of code unless WithHistoryLevel is set, and finer level than it is loaded from store
+func (c *Cerebro) loadHistory(code string) ([][]container.Candle, error) {
+	comps := c.compress[code]
+	base, leftEdge := c.historyLevel, c.historyLeftEdge
+	if base == 0 {
+		for _, comp := range comps {
+			if comp.bar.Type == container.BarTime && (base == 0 || comp.level < base) {
+				base, leftEdge = comp.level, comp.LeftEdge
+			}
+		}
+	}
+
+	history := make([][]container.Candle, len(comps))
+	if base == 0 {
+		return history, nil
+	}
+
+	baseCandles, err := c.fetchHistory(code, base)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, comp := range comps {
+		if comp.bar.Type != container.BarTime {
+			continue
+		}
+
+		if comp.level < base {
+			if history[i], err = c.fetchHistory(code, comp.level); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		candles := baseCandles
+		if comp.LeftEdge != leftEdge {
+			// move date to other edge of period
+			shift := base
+			if comp.LeftEdge {
+				shift = -base
+			}
+			candles = make([]container.Candle, len(baseCandles))
+			for j, candle := range baseCandles {
+				candle.Date = candle.Date.Add(shift)
+				candles[j] = candle
+			}
+		}
+
+		if comp.level == base {
+			history[i] = candles
+			continue
+		}
+		history[i] = Resample(candles, comp.level, comp.LeftEdge, comp.opts...)
+	}
+	return history, nil
+}
+
+func (c *Cerebro) fetchHistory(code string, level time.Duration) ([]container.Candle, error) {
+	var candles []container.Candle
+	err := pkg.Retry(10, func() error {
+		var err error
+		candles, err = c.store.LoadHistory(c.Ctx, code, level)
+		if err != nil {
+			c.Logger.Error(err)
+		}
+		return err
+	})
+	return candles, err
+}
+
+// bar is history candle waiting for replay in backtest
+type bar struct {
+	con    container.Container
+	candle container.Candle
+	// end time of candle period, bars are replayed in order of end time
+	end time.Time
+	// level of candle, higher level is delivered first among bars of same end time
+	level time.Duration
+	// feed is true for finest level of code, only these candles are fed to broker
+	feed bool
+}
+
+// finestLevel is lowest level among time bars of code
+func finestLevel(comps []CompressInfo) time.Duration {
+	var finest time.Duration
+	for _, comp := range comps {
+		if comp.bar.Type != container.BarTime {
+			continue
+		}
+		if finest == 0 || comp.level < finest {
+			finest = comp.level
+		}
+	}
+	return finest
+}
+
+// loadBacktest replay history candle in chronological order
+// candles before backtest range are only added to container for warming up
+// candles of same end time are fed to broker and added to containers together,
+// and then delivered to strategies from higher level one by one
+// it waits all event of previous candle is handled before next candle
+func (c *Cerebro) loadBacktest() error {
+	if c.store == nil {
+		return error2.ErrStoreNotExists
+	}
+
+	var bars []bar
+	for _, code := range c.codes {
+		finest := finestLevel(c.compress[code])
+		history, err := c.loadHistory(code)
+		if err != nil {
+			return err
+		}
+
+		for i, comp := range c.compress[code] {
+			if comp.bar.Type != container.BarTime {
+				c.Logger.Warningf("%s bar of %s is not supported in backtest", comp.bar, code)
+				continue
+			}
+
+			con := c.getContainer(code, comp.bar)
+			for _, candle := range history[i] {
+				if candle.Date.Before(c.from) {
+					con.Add(candle)
+					continue
+				}
+
+				if !c.to.IsZero() && candle.Date.After(c.to) {
+					continue
+				}
+
+				end := candle.Date
+				if comp.LeftEdge {
+					end = end.Add(comp.level)
 				}
+				bars = append(bars, bar{con: con, candle: candle, end: end, level: comp.level, feed: comp.level == finest})
 			}
 		}
 	}
 
+	sort.SliceStable(bars, func(i, j int) bool {
+		if !bars[i].end.Equal(bars[j].end) {
+			return bars[i].end.Before(bars[j].end)
+		}
+		return bars[i].level > bars[j].level
+	})
+
+	for i := 0; i < len(bars); {
+		j := i + 1
+		for j < len(bars) && bars[j].end.Equal(bars[i].end) {
+			j++
+		}
+		if err := c.replay(bars[i:j]); err != nil {
+			return err
+		}
+		i = j
+	}
+	return nil
+}
+
+// replay deliver bars of same end time
+func (c *Cerebro) replay(bars []bar) error {
+	for _, b := range bars {
+		if b.feed {
+			c.broker.NextCandle(b.candle)
+			c.eventEngine.Wait()
+		}
+	}
+
+	for _, b := range bars {
+		b.con.Add(b.candle)
+	}
+
+	if c.clock != nil {
+		return c.replayStep(bars)
+	}
+
+	for _, b := range bars {
+		select {
+		case <-c.Ctx.Done():
+			return c.Ctx.Err()
+		default:
+		}
+
+		c.strategyEngine.Next(b.con)
+		c.eventEngine.Wait()
+		c.analysisEngine.Next(b.con)
+	}
+	return nil
+}
+
+// replayStep deliver bars of same end time as one step of every level
+func (c *Cerebro) replayStep(bars []bar) error {
+	for i := 0; i < len(bars); {
+		j := i + 1
+		for j < len(bars) && bars[j].level == bars[i].level {
+			j++
+		}
+
+		select {
+		case <-c.Ctx.Done():
+			return c.Ctx.Err()
+		default:
+		}
+
+		key := stepKey{end: bars[i].end, level: bars[i].level}
+		stepBars := make([]stepBar, j-i)
+		for k, b := range bars[i:j] {
+			stepBars[k] = stepBar{feed: feed{con: b.con}, candle: b.candle, end: b.end}
+		}
+		step := newStep(key, stepBars, c.clock.feeds[key.level], c.clock.fill)
+		c.strategyEngine.Step(step)
+		c.eventEngine.Wait()
+		for _, con := range step.Containers {
+			c.analysisEngine.Next(con)
+		}
+		i = j
+	}
 	return nil
 }
 
@@ -222,6 +593,7 @@ func (c *Cerebro) load() error {
 func (c *Cerebro) registerEvent() {
 	c.eventEngine.Register <- c.strategyEngine
 	c.eventEngine.Register <- c.broker
+	c.eventEngine.Register <- c.analysisEngine
 }
 
 func (c *Cerebro) createContainer() {
@@ -231,6 +603,7 @@ func (c *Cerebro) createContainer() {
 			c.containers = append(c.containers, container.NewDataContainer(container.Info{
 				Code:             i,
 				CompressionLevel: j.level,
+				Bar:              j.bar,
 			}))
 		}
 	}
@@ -241,8 +614,9 @@ func (c *Cerebro) createContainer() {
 // second load from store data
 // third other engine setup
 func (c *Cerebro) Start() error {
-	done := make(chan os.Signal)
+	done := make(chan os.Signal, 1)
 	signal.Notify(done, syscall.SIGTERM)
+	defer signal.Stop(done)
 
 	validate := validator.New()
 	if err := validate.Struct(c); err != nil {
@@ -251,13 +625,33 @@ func (c *Cerebro) Start() error {
 	}
 
 	c.createContainer()
-	c.chart.Start()
+	// chart is only for live data, and backtests can run in parallel without its http server
+	if !c.backtest {
+		c.chart.Start()
+	}
 
 	c.eventEngine.Start(c.Ctx)
 	c.registerEvent()
 	c.Logger.Info("Cerebro start ...")
 	c.broker.Store = c.store
+	// cash and commission of broker and simulator are same, option of cerebro override setting of simulator
+	if sim, ok := c.store.(store.Simulator); ok {
+		if c.broker.Cash != 0 {
+			sim.SetCash(c.broker.Cash)
+		} else {
+			c.broker.Cash = sim.Cash()
+		}
+		if c.broker.Commission != 0 {
+			sim.SetCommission(c.broker.Commission)
+		} else {
+			c.broker.Commission = sim.Commission()
+		}
+	}
 	c.strategyEngine.Broker = c.broker
+	c.strategyEngine.SetFeeds(c)
+	if c.clock != nil {
+		c.registerClock()
+	}
 	c.strategyEngine.Start(c.Ctx, c.dataCh)
 
 	c.broker.SetEventBroadCaster(c.eventEngine)
@@ -268,6 +662,11 @@ func (c *Cerebro) Start() error {
 		return err
 	}
 
+	if c.backtest {
+		c.Logger.Info("backtest finished")
+		return c.Stop()
+	}
+
 	select {
 	case <-c.Ctx.Done():
 		break
@@ -278,7 +677,18 @@ func (c *Cerebro) Start() error {
 }
 
 //Stop all cerebro goroutine and finish
+// analyzers are stopped before, so their results are ready after Stop
 func (c *Cerebro) Stop() error {
+	c.analysisEngine.Stop()
 	c.Cancel()
+	c.live.Wait()
+	if c.recorder != nil {
+		return c.recorder.Close()
+	}
 	return nil
 }
+
+// Results is result of every analyzer by its name, it is filled after cerebro is stopped
+func (c *Cerebro) Results() map[string]analysis.Result {
+	return c.analysisEngine.Results()
+}
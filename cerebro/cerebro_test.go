@@ -17,14 +17,26 @@ package cerebro
 
 import (
 	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/gobenpark/trader/analysis"
+	"github.com/gobenpark/trader/broker"
 	"github.com/gobenpark/trader/container"
 	"github.com/gobenpark/trader/event"
+	"github.com/gobenpark/trader/observer"
 	"github.com/gobenpark/trader/order"
 	"github.com/gobenpark/trader/position"
+	"github.com/gobenpark/trader/store/replay"
+	"github.com/gobenpark/trader/store/simulator"
+	"github.com/gobenpark/trader/strategy"
+	"github.com/gobenpark/trader/trade"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type SampleStore struct {
@@ -65,7 +77,7 @@ func (s SampleStore) Uid() string {
 }
 
 func (s SampleStore) Cash() int64 {
-	panic("implement me")
+	return 0
 }
 
 func (s SampleStore) Commission() float64 {
@@ -73,7 +85,7 @@ func (s SampleStore) Commission() float64 {
 }
 
 func (s SampleStore) Positions() []position.Position {
-	panic("implement me")
+	return nil
 }
 
 func (s SampleStore) OrderState(ctx context.Context) (<-chan event.OrderEvent, error) {
@@ -84,6 +96,267 @@ func (s SampleStore) OrderInfo(id string) (*order.Order, error) {
 	panic("implement me")
 }
 
+type HistoryStore struct {
+	SampleStore
+	start time.Time
+}
+
+func (s HistoryStore) LoadHistory(ctx context.Context, code string, d time.Duration) ([]container.Candle, error) {
+	var candles []container.Candle
+	for i := 0; i < 10; i++ {
+		candles = append(candles, container.Candle{
+			Code:   code,
+			Open:   float64(i),
+			High:   float64(i),
+			Low:    float64(i),
+			Close:  float64(i),
+			Volume: float64(i),
+			Date:   s.start.Add(d * time.Duration(i)),
+		})
+	}
+	return candles, nil
+}
+
+func (s HistoryStore) OrderState(ctx context.Context) (<-chan event.OrderEvent, error) {
+	ch := make(chan event.OrderEvent)
+	go func() {
+		<-ctx.Done()
+		close(ch)
+	}()
+	return ch, nil
+}
+
+type recordStrategy struct {
+	candles []container.Candle
+	ends    []time.Time
+	sizes   []int
+}
+
+func (r *recordStrategy) Next(broker *broker.Broker, container container.Container) {
+	candle := container.Values()[0]
+	r.candles = append(r.candles, candle)
+	r.ends = append(r.ends, candle.Date.Add(container.Level()))
+	r.sizes = append(r.sizes, container.Size())
+}
+
+func (r *recordStrategy) NotifyOrder(o *order.Order) {}
+
+func (r *recordStrategy) NotifyTrade(t *trade.Trade) {}
+
+func (r *recordStrategy) NotifyCashValue(cash, value float64) {}
+
+func (r *recordStrategy) NotifyFund(fund *broker.Fund) {}
+
+type buyStrategy struct {
+	recordStrategy
+}
+
+func (b *buyStrategy) Next(broker *broker.Broker, container container.Container) {
+	if len(b.candles) == 0 {
+		broker.Buy(container.Code(), 10, 0, order.Market)
+	}
+	b.recordStrategy.Next(broker, container)
+}
+
+type feedStrategy struct {
+	recordStrategy
+	feeds  strategy.Feeds
+	levels []time.Duration
+	higher []int
+}
+
+func (f *feedStrategy) SetFeeds(feeds strategy.Feeds) {
+	f.feeds = feeds
+}
+
+func (f *feedStrategy) Next(broker *broker.Broker, container container.Container) {
+	f.levels = append(f.levels, container.Level())
+	f.higher = append(f.higher, f.feeds.Feed(container.Code(), time.Minute*3).Size())
+	f.recordStrategy.Next(broker, container)
+}
+
+// sparseStore has no candle of test2 at third minute
+type sparseStore struct {
+	HistoryStore
+}
+
+func (s sparseStore) LoadHistory(ctx context.Context, code string, d time.Duration) ([]container.Candle, error) {
+	candles, err := s.HistoryStore.LoadHistory(ctx, code, d)
+	if code == "test2" {
+		candles = append(candles[:2], candles[3:]...)
+	}
+	return candles, err
+}
+
+// gapStore fall under 90 at fourth bar and open with gap down at fifth bar
+type gapStore struct {
+	HistoryStore
+}
+
+func (s gapStore) LoadHistory(ctx context.Context, code string, d time.Duration) ([]container.Candle, error) {
+	var candles []container.Candle
+	for i := 0; i < 6; i++ {
+		c := container.Candle{Code: code, Open: 100, High: 100, Low: 100, Close: 100, Volume: 10, Date: s.start.Add(d * time.Duration(i))}
+		switch i {
+		case 3:
+			c.Low, c.Close = 90, 92
+		case 4, 5:
+			c.Open, c.High, c.Low, c.Close = 70, 72, 68, 70
+		}
+		candles = append(candles, c)
+	}
+	return candles, nil
+}
+
+type stopStrategy struct {
+	recordStrategy
+	filled []*order.Order
+}
+
+func (s *stopStrategy) Next(broker *broker.Broker, container container.Container) {
+	if len(s.candles) == 0 {
+		broker.Sell(container.Code(), 10, 95, order.Stop)
+	}
+	s.recordStrategy.Next(broker, container)
+}
+
+func (s *stopStrategy) NotifyOrder(o *order.Order) {
+	if o.Status() == order.Completed {
+		s.filled = append(s.filled, o)
+	}
+}
+
+// minuteStore send a tick every minute of tick time, so every tick close candle of minute
+type minuteStore struct {
+	HistoryStore
+	// interval is wall time between ticks
+	interval time.Duration
+}
+
+func (s minuteStore) LoadTick(ctx context.Context, code string) (<-chan container.Tick, error) {
+	ch := make(chan container.Tick)
+	go func() {
+		defer close(ch)
+		for i := 0; i < 10; i++ {
+			time.Sleep(s.interval)
+			select {
+			case ch <- container.Tick{Code: code, Date: s.start.Add(time.Minute * time.Duration(i)), Price: float64(i + 1), Volume: 1}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// offsetStore send ticks at start plus each offset, price of tick is its order from 1
+type offsetStore struct {
+	HistoryStore
+	offsets []time.Duration
+	// interval is wall time between ticks
+	interval time.Duration
+}
+
+func (s offsetStore) LoadTick(ctx context.Context, code string) (<-chan container.Tick, error) {
+	ch := make(chan container.Tick)
+	go func() {
+		defer close(ch)
+		for k, i := range s.offsets {
+			time.Sleep(s.interval)
+			select {
+			case ch <- container.Tick{Code: code, Date: s.start.Add(i), Price: float64(k + 1), Volume: 1}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// candleStrategy record delivered candles, it can be read while cerebro is running
+type candleStrategy struct {
+	recordStrategy
+	mu sync.Mutex
+}
+
+func (s *candleStrategy) Next(broker *broker.Broker, container container.Container) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recordStrategy.Next(broker, container)
+}
+
+func (s *candleStrategy) delivered() []container.Candle {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]container.Candle(nil), s.candles...)
+}
+
+type cashStrategy struct {
+	recordStrategy
+	mu     sync.Mutex
+	values []float64
+}
+
+func (s *cashStrategy) NotifyCashValue(cash, value float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values = append(s.values, value)
+}
+
+func (s *cashStrategy) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.values)
+}
+
+// tradeStrategy buy at first candle and sell at fifth candle
+type tradeStrategy struct {
+	cashStrategy
+	closed []trade.Trade
+}
+
+func (s *tradeStrategy) Next(broker *broker.Broker, container container.Container) {
+	switch len(s.candles) {
+	case 0:
+		broker.Buy(container.Code(), 10, 0, order.Market)
+	case 4:
+		broker.Sell(container.Code(), 10, 0, order.Market)
+	}
+	s.recordStrategy.Next(broker, container)
+}
+
+func (s *tradeStrategy) NotifyTrade(t *trade.Trade) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t.Status == trade.Closed {
+		s.closed = append(s.closed, *t)
+	}
+}
+
+func (s *tradeStrategy) trades() []trade.Trade {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]trade.Trade(nil), s.closed...)
+}
+
+type stepStrategy struct {
+	recordStrategy
+	steps  []strategy.Step
+	counts []int
+}
+
+func (s *stepStrategy) NextStep(broker *broker.Broker, step *strategy.Step) {
+	s.steps = append(s.steps, *step)
+	s.counts = append(s.counts, len(step.Containers))
+}
+
+// startBroker connect broker to store and event engine for test which load data without Start
+func startBroker(c *Cerebro) {
+	c.eventEngine.Start(c.Ctx)
+	c.broker.Store = c.store
+	c.broker.SetEventBroadCaster(c.eventEngine)
+}
+
 func TestNewCerebro(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -125,6 +398,13 @@ func TestNewCerebro(t *testing.T) {
 				assert.True(t, c.preload)
 			},
 		},
+		{
+			"backtest",
+			NewCerebro(WithBacktest(time.Time{}, time.Time{})),
+			func(c *Cerebro, t *testing.T) {
+				assert.True(t, c.backtest)
+			},
+		},
 		{
 			"resample",
 			NewCerebro(),
@@ -158,7 +438,7 @@ func TestNewCerebro(t *testing.T) {
 			"container not exist",
 			NewCerebro(),
 			func(c *Cerebro, t *testing.T) {
-				assert.Nil(t, c.getContainer("nil", time.Second*0))
+				assert.Nil(t, c.getContainer("nil", container.TimeBar(time.Second*0)))
 				assert.Nil(t, c.containers)
 			},
 		},
@@ -171,7 +451,7 @@ func TestNewCerebro(t *testing.T) {
 				return c
 			}(),
 			func(c *Cerebro, t *testing.T) {
-				con := c.getContainer("test1", time.Minute*0)
+				con := c.getContainer("test1", container.TimeBar(time.Minute*0))
 				assert.NotNil(t, con)
 			},
 		},
@@ -235,6 +515,7 @@ func TestNewCerebro(t *testing.T) {
 					WithLive(true),
 				)
 				c.createContainer()
+				startBroker(c)
 				return c
 			}(),
 			func(c *Cerebro, t *testing.T) {
@@ -250,9 +531,426 @@ func TestNewCerebro(t *testing.T) {
 	}
 }
 
+func TestCerebro_Recorder(t *testing.T) {
+	dir := t.TempDir()
+	c := NewCerebro(
+		WithStore(SampleStore{}, "test1"),
+		WithResample("test1", time.Minute, true),
+		WithResample("test1", time.Minute*3, true),
+		WithRecorder(observer.NewRecorder(dir)),
+		WithLive(true),
+	)
+	c.createContainer()
+	startBroker(c)
+	assert.NoError(t, c.load())
+
+	time.Sleep(time.Millisecond * 200)
+	assert.NoError(t, c.Stop())
+
+	files, err := filepath.Glob(filepath.Join(dir, "tick", "test1", "*.csv"))
+	assert.NoError(t, err)
+	rows := 0
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		assert.NoError(t, err)
+		rows += strings.Count(string(data), "\n") - 1
+	}
+
+	// every tick is recorded once even if code has several compression levels
+	assert.Equal(t, 10, rows)
+}
+
+func TestCerebro_LiveFund(t *testing.T) {
+	start := time.Date(2021, 3, 12, 0, 0, 0, 0, time.UTC)
+	st := &cashStrategy{}
+	c := NewCerebro(
+		WithStore(minuteStore{HistoryStore{start: start}, 0}, "test1"),
+		WithStrategy(st),
+		WithResample("test1", time.Minute, true),
+		WithResample("test1", time.Minute*3, true),
+		WithLive(true),
+	)
+	go c.Start()
+	defer c.Stop()
+
+	// fund is notified once for every closed candle of finest level
+	assert.Eventually(t, func() bool {
+		return st.count() == 10
+	}, time.Second*5, time.Millisecond*10)
+	time.Sleep(time.Millisecond * 50)
+	assert.Equal(t, 10, st.count())
+}
+
+func TestCerebro_LiveTrade(t *testing.T) {
+	start := time.Date(2021, 3, 12, 0, 0, 0, 0, time.UTC)
+	st := &tradeStrategy{}
+	c := NewCerebro(
+		WithStore(simulator.NewStore(minuteStore{HistoryStore{start: start}, time.Millisecond * 20}, 0, 0), "test1"),
+		WithStrategy(st),
+		WithCash(1000),
+		WithResample("test1", time.Minute, true),
+		WithLive(true),
+	)
+	go c.Start()
+	defer c.Stop()
+
+	assert.Eventually(t, func() bool {
+		return len(st.trades()) == 1
+	}, time.Second*5, time.Millisecond*10)
+
+	// buy is filled with tick of third minute and sell with tick of seventh minute
+	// so trade is open during candles of third ~ sixth minute
+	trades := st.trades()
+	require.Len(t, trades, 1)
+	assert.Equal(t, 4, trades[0].BarLen)
+	assert.Equal(t, float64(3), trades[0].EntryPrice)
+	assert.Equal(t, float64(7), trades[0].ExitPrice)
+}
+
+func TestCerebro_LiveEquity(t *testing.T) {
+	start := time.Date(2021, 3, 12, 0, 0, 0, 0, time.UTC)
+	equity := analysis.NewEquity()
+	c := NewCerebro(
+		WithStore(simulator.NewStore(minuteStore{HistoryStore{start: start}, time.Millisecond * 20}, 0, 0), "test1"),
+		WithStrategy(&tradeStrategy{}),
+		WithCash(1000),
+		WithResample("test1", time.Minute, true),
+		WithLive(true),
+		WithAnalyzer(equity),
+	)
+	go c.Start()
+	time.Sleep(time.Millisecond * 400)
+	assert.NoError(t, c.Stop())
+
+	// executions between bars are merged, so there is one point for every minute candle at its end
+	curve := equity.Curve()
+	require.Len(t, curve, 10)
+	for i, p := range curve {
+		assert.Equal(t, start.Add(time.Minute*time.Duration(i+1)), p.Date)
+	}
+	assert.Equal(t, float64(1040), curve[9].Equity)
+}
+
+func TestCerebro_LiveEquityInBar(t *testing.T) {
+	start := time.Date(2021, 3, 12, 0, 0, 0, 0, time.UTC)
+	var offsets []time.Duration
+	for i := 0; i < 9; i++ {
+		offsets = append(offsets, time.Second*20*time.Duration(i))
+	}
+	equity := analysis.NewEquity()
+	c := NewCerebro(
+		WithStore(simulator.NewStore(offsetStore{HistoryStore{start: start}, offsets, time.Millisecond * 20}, 0, 0), "test1"),
+		WithStrategy(&tradeStrategy{}),
+		WithCash(1000),
+		WithResample("test1", time.Minute, true),
+		WithLive(true),
+		WithAnalyzer(equity),
+	)
+	go c.Start()
+	time.Sleep(time.Millisecond * 400)
+	assert.NoError(t, c.Stop())
+
+	// buy is filled with tick of 00:01:20 in second candle, and it is merged to point of that candle
+	curve := equity.Curve()
+	require.Len(t, curve, 3)
+	for i, p := range curve {
+		assert.Equal(t, start.Add(time.Minute*time.Duration(i+1)), p.Date)
+	}
+	assert.Equal(t, float64(1000), curve[0].Equity)
+	assert.Equal(t, float64(950), curve[1].Cash)
+	assert.Equal(t, float64(1010), curve[1].Equity)
+	assert.Equal(t, float64(1040), curve[2].Equity)
+}
+
+func TestCerebro_ReplayPause(t *testing.T) {
+	start := time.Date(2021, 3, 12, 0, 0, 0, 0, time.UTC)
+	data := offsetStore{HistoryStore{start: start}, []time.Duration{0, time.Millisecond * 200, time.Millisecond * 900}, 0}
+	rs := replay.NewStore(data, replay.WithPause())
+	st := &candleStrategy{}
+	c := NewCerebro(
+		WithStore(rs, "test1"),
+		WithStrategy(st),
+		WithResample("test1", time.Second, true),
+		WithLive(true),
+	)
+	go c.Start()
+	defer c.Stop()
+
+	// candle is closed by virtual time of replay, so it stays open while replay is paused
+	rs.Step()
+	time.Sleep(time.Millisecond * 1500)
+	assert.Empty(t, st.delivered())
+
+	rs.Resume()
+	assert.Eventually(t, func() bool {
+		return len(st.delivered()) == 1
+	}, time.Second*5, time.Millisecond*10)
+	candles := st.delivered()
+	assert.Equal(t, start, candles[0].Date)
+	assert.Equal(t, float64(3), candles[0].Volume)
+}
+
+func TestCerebro_LoadHistory(t *testing.T) {
+	start := time.Date(2021, 3, 12, 0, 0, 0, 0, time.UTC)
+	c := NewCerebro(
+		WithStore(HistoryStore{start: start}, "test1"),
+		WithResample("test1", time.Minute*3, true),
+		WithResample("test1", time.Minute, true),
+		WithResample("test1", time.Minute*3, false),
+	)
+
+	history, err := c.loadHistory("test1")
+	assert.NoError(t, err)
+	assert.Len(t, history[1], 10)
+
+	// 3 minute candles are resampled from 1 minute history
+	assert.Len(t, history[0], 4)
+	assert.Equal(t, container.Candle{Code: "test1", Open: 0, High: 2, Low: 0, Close: 2, Volume: 3, Date: start}, history[0][0])
+
+	// 1 minute candle of 00:00 is 00:01 of right edge
+	assert.Len(t, history[2], 4)
+	assert.Equal(t, container.Candle{Code: "test1", Open: 0, High: 2, Low: 0, Close: 2, Volume: 3, Date: start.Add(3 * time.Minute)}, history[2][0])
+}
+
+func TestCerebro_BacktestFeeds(t *testing.T) {
+	start := time.Date(2021, 3, 12, 0, 0, 0, 0, time.UTC)
+	st := &feedStrategy{}
+	c := NewCerebro(
+		WithStore(HistoryStore{start: start}, "test1"),
+		WithStrategy(st),
+		WithResample("test1", time.Minute, true),
+		WithResample("test1", time.Minute*3, true),
+		WithBacktest(start, start.Add(time.Minute*5)),
+	)
+	assert.NoError(t, c.Start())
+
+	// 3 minute candle is delivered before 1 minute candle of same end time
+	m1, m3 := time.Minute, time.Minute*3
+	assert.Equal(t, []time.Duration{m1, m1, m3, m1, m1, m1, m3, m1}, st.levels)
+	assert.Equal(t, []int{0, 0, 1, 1, 1, 1, 2, 2}, st.higher)
+	assert.Nil(t, c.Feed("test1", time.Hour))
+	assert.NotNil(t, c.Bar("test1", container.TimeBar(time.Minute)))
+}
+
+func TestCerebro_Deliver(t *testing.T) {
+	c := NewCerebro(WithStore(SampleStore{}, "test1"))
+	startBroker(c)
+	c.dataCh = make(chan container.Container, 2)
+	go func() {
+		for range c.chart.Input {
+		}
+	}()
+	defer close(c.chart.Input)
+
+	date := time.Date(2021, 3, 12, 0, 3, 0, 0, time.UTC)
+	m1 := container.NewDataContainer(container.Info{Code: "test1", CompressionLevel: time.Minute})
+	m3 := container.NewDataContainer(container.Info{Code: "test1", CompressionLevel: time.Minute * 3})
+	c.deliver([]feed{
+		{info: CompressInfo{level: time.Minute, bar: container.TimeBar(time.Minute)}, con: m1},
+		{info: CompressInfo{level: time.Minute * 3, bar: container.TimeBar(time.Minute * 3)}, con: m3},
+	}, [][]container.Candle{
+		{{Code: "test1", Close: 1, Date: date}},
+		{{Code: "test1", Close: 1, Date: date}},
+	})
+
+	first := <-c.dataCh
+	assert.Equal(t, time.Minute*3, first.Level())
+	// lower container is already updated when higher one is delivered
+	assert.Equal(t, 1, m1.Size())
+	assert.Equal(t, time.Minute, (<-c.dataCh).Level())
+}
+
+func TestCerebro_BacktestStep(t *testing.T) {
+	start := time.Date(2021, 3, 12, 0, 0, 0, 0, time.UTC)
+	st := &stepStrategy{}
+	c := NewCerebro(
+		WithStore(sparseStore{HistoryStore{start: start}}, "test1", "test2"),
+		WithStrategy(st),
+		WithResample("test1", time.Minute, true),
+		WithResample("test2", time.Minute, true),
+		WithStepClock(FillForward, 0),
+		WithBacktest(start, start.Add(time.Minute*4)),
+	)
+	assert.NoError(t, c.Start())
+
+	// one step of every minute with candles of both codes
+	assert.Len(t, st.steps, 5)
+	assert.Equal(t, []int{2, 2, 1, 2, 2}, st.counts)
+	assert.Equal(t, start.Add(time.Minute*3), st.steps[2].Date)
+	assert.Equal(t, []string{"test2"}, st.steps[2].Missing)
+
+	// missing candle is forward filled
+	values := c.Feed("test2", time.Minute).Values()
+	assert.Len(t, values, 5)
+	assert.Equal(t, container.Candle{Code: "test2", Open: 1, High: 1, Low: 1, Close: 1, Date: start.Add(time.Minute * 2)}, values[2])
+}
+
+func TestStepClock(t *testing.T) {
+	end := time.Date(2021, 3, 12, 0, 1, 0, 0, time.UTC)
+	now := time.Now()
+	con := func(code string) feed {
+		return feed{
+			info: CompressInfo{level: time.Minute, bar: container.TimeBar(time.Minute), LeftEdge: true},
+			con:  container.NewDataContainer(container.Info{Code: code, CompressionLevel: time.Minute}),
+		}
+	}
+	a, b := con("a"), con("b")
+	bar := func(f feed, end time.Time) stepBar {
+		return newStepBar(f, container.Candle{Code: f.con.Code(), Close: 1, Date: end.Add(-time.Minute)})
+	}
+
+	s := newStepClock(FillNone, time.Second)
+	s.register(a)
+	s.register(b)
+
+	t.Run("every code", func(t *testing.T) {
+		assert.Empty(t, s.next(bar(a, end), now))
+		steps := s.next(bar(b, end), now)
+		assert.Len(t, steps, 1)
+		assert.Len(t, steps[0].Containers, 2)
+		assert.Empty(t, steps[0].Missing)
+	})
+
+	t.Run("later candle", func(t *testing.T) {
+		assert.Empty(t, s.next(bar(a, end.Add(time.Minute)), now))
+		steps := s.next(bar(a, end.Add(2*time.Minute)), now)
+		assert.Len(t, steps, 1)
+		assert.Equal(t, end.Add(time.Minute), steps[0].Date)
+		assert.Equal(t, []string{"b"}, steps[0].Missing)
+	})
+
+	t.Run("grace", func(t *testing.T) {
+		assert.Empty(t, s.expire(now))
+		steps := s.expire(now.Add(time.Second))
+		assert.Len(t, steps, 1)
+		assert.Equal(t, end.Add(2*time.Minute), steps[0].Date)
+		assert.Equal(t, []string{"b"}, steps[0].Missing)
+	})
+}
+
 func TestCerebro_Stop(t *testing.T) {
 	c := NewCerebro()
 	err := c.Stop()
 	assert.NoError(t, err)
 	assert.Equal(t, "context canceled", c.Ctx.Err().Error())
 }
+
+func TestCerebro_Backtest(t *testing.T) {
+	start := time.Date(2021, 3, 12, 0, 0, 0, 0, time.UTC)
+	st := &recordStrategy{}
+	c := NewCerebro(
+		WithStore(HistoryStore{start: start}, "test1", "test2"),
+		WithStrategy(st),
+		WithResample("test1", time.Minute*3, true),
+		WithResample("test2", time.Minute, true),
+		WithBacktest(start.Add(time.Minute*3), start.Add(time.Minute*9)),
+	)
+
+	done := make(chan error)
+	go func() {
+		done <- c.Start()
+	}()
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second * 5):
+		t.Fatal("backtest does not finish")
+	}
+
+	// test1 3, 6, 9 minute and test2 3 ~ 9 minute
+	assert.Len(t, st.candles, 10)
+	for i := 1; i < len(st.ends); i++ {
+		assert.False(t, st.ends[i].Before(st.ends[i-1]))
+	}
+	assert.Equal(t, "test2", st.candles[0].Code)
+	assert.Equal(t, 4, st.sizes[0])
+	assert.Equal(t, context.Canceled, c.Ctx.Err())
+}
+
+func TestCerebro_BacktestSimulator(t *testing.T) {
+	start := time.Date(2021, 3, 12, 0, 0, 0, 0, time.UTC)
+	sim := simulator.NewStore(HistoryStore{start: start}, 0, 0)
+	st := &buyStrategy{}
+	c := NewCerebro(
+		WithStore(sim, "test1"),
+		WithStrategy(st),
+		WithCash(1000),
+		WithCommission(0.1),
+		WithResample("test1", time.Minute, true),
+		WithBacktest(start.Add(time.Minute*3), time.Time{}),
+	)
+
+	assert.NoError(t, c.Start())
+	assert.Len(t, st.candles, 7)
+
+	// market order at third minute is filled with open of fourth minute
+	p := sim.Positions()
+	assert.Len(t, p, 1)
+	assert.Equal(t, int64(10), p[0].Size)
+	assert.Equal(t, float64(4), p[0].Price)
+	assert.Equal(t, int64(956), sim.Cash())
+}
+
+func TestCerebro_BacktestStopOrder(t *testing.T) {
+	start := time.Date(2021, 3, 12, 0, 0, 0, 0, time.UTC)
+	sim := simulator.NewStore(gapStore{HistoryStore{start: start}}, 0, 0)
+	st := &stopStrategy{}
+	c := NewCerebro(
+		WithStore(sim, "test1"),
+		WithStrategy(st),
+		WithCash(10000),
+		WithResample("test1", time.Hour, true),
+		WithBacktest(start, time.Time{}),
+	)
+
+	assert.NoError(t, c.Start())
+
+	// stop order is filled with stop price at candle which touch it, not with open of next candle
+	require.Len(t, st.filled, 1)
+	assert.Equal(t, float64(95), st.filled[0].ExecutedPrice)
+	assert.Equal(t, start.Add(time.Hour*3), st.filled[0].ExecutedAt)
+}
+
+func TestCerebro_SimulatorCommission(t *testing.T) {
+	start := time.Date(2021, 3, 12, 0, 0, 0, 0, time.UTC)
+	sim := simulator.NewStore(HistoryStore{start: start}, 1000, 0.1)
+	c := NewCerebro(
+		WithStore(sim, "test1"),
+		WithStrategy(&buyStrategy{}),
+		WithResample("test1", time.Minute, true),
+		WithBacktest(start.Add(time.Minute*3), time.Time{}),
+	)
+
+	assert.NoError(t, c.Start())
+
+	// setting of simulator is used by broker when cerebro does not set it
+	assert.Equal(t, int64(1000), c.broker.Cash)
+	assert.Equal(t, 0.1, c.broker.Commission)
+	p := c.broker.GetPosition("test1")
+	assert.Equal(t, int64(10), p.Size)
+	assert.InDelta(t, 4, p.Commission, 1e-9)
+	assert.Equal(t, int64(956), sim.Cash())
+}
+
+func TestCerebro_Analyzer(t *testing.T) {
+	start := time.Date(2021, 3, 12, 0, 0, 0, 0, time.UTC)
+	sim := simulator.NewStore(HistoryStore{start: start}, 0, 0)
+	c := NewCerebro(
+		WithStore(sim, "test1"),
+		WithStrategy(&buyStrategy{}),
+		WithCash(1000),
+		WithCommission(0.1),
+		WithResample("test1", time.Minute, true),
+		WithBacktest(start.Add(time.Minute*3), time.Time{}),
+		WithAnalyzer(analysis.NewReturns(), analysis.NewTradeAnalyzer(), analysis.NewExposure()),
+	)
+
+	assert.NoError(t, c.Start())
+
+	results := c.Results()
+	assert.Len(t, results, 3)
+	assert.InDelta(t, 0.046, results["returns"]["total"], 1e-9)
+	assert.Equal(t, float64(0), results["trade"]["total"])
+	assert.InDelta(t, 5.0/6.0, results["exposure"]["exposure"], 1e-9)
+}
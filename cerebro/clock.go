@@ -0,0 +1,233 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package cerebro
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/gobenpark/trader/container"
+	"github.com/gobenpark/trader/strategy"
+)
+
+// stepBar is latest candle of feed closed at end
+type stepBar struct {
+	feed
+	candle container.Candle
+	end    time.Time
+}
+
+func newStepBar(f feed, candle container.Candle) stepBar {
+	end := candle.Date
+	if f.info.LeftEdge {
+		end = end.Add(f.info.level)
+	}
+	return stepBar{feed: f, candle: candle, end: end}
+}
+
+type stepKey struct {
+	end   time.Time
+	level time.Duration
+}
+
+// pendingStep is step waiting candles of other codes
+type pendingStep struct {
+	bars     []stepBar
+	deadline time.Time
+}
+
+// add candle to step, later candle of same code replaces earlier one
+func (p *pendingStep) add(b stepBar) {
+	for i := range p.bars {
+		if p.bars[i].con == b.con {
+			p.bars[i] = b
+			return
+		}
+	}
+	p.bars = append(p.bars, b)
+}
+
+// stepClock align candles of every code by end time and level, and release them as one step
+// step is released when every code has candle, candle of later time arrives or grace is passed
+type stepClock struct {
+	fill  Fill
+	grace time.Duration
+	// feeds is time feeds of every code by level
+	feeds   map[time.Duration][]feed
+	in      chan stepBar
+	pending map[stepKey]*pendingStep
+}
+
+func newStepClock(fill Fill, grace time.Duration) *stepClock {
+	return &stepClock{
+		fill:    fill,
+		grace:   grace,
+		feeds:   make(map[time.Duration][]feed),
+		in:      make(chan stepBar),
+		pending: make(map[stepKey]*pendingStep),
+	}
+}
+
+// register add time feed of code which steps wait
+func (s *stepClock) register(f feed) {
+	s.feeds[f.info.level] = append(s.feeds[f.info.level], f)
+}
+
+func (s *stepClock) add(ctx context.Context, b stepBar) {
+	select {
+	case s.in <- b:
+	case <-ctx.Done():
+	}
+}
+
+// run release steps to release until ctx is done
+func (s *stepClock) run(ctx context.Context, release func(step *strategy.Step)) {
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	for {
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		var timeout <-chan time.Time
+		if deadline, ok := s.deadline(); ok {
+			timer.Reset(time.Until(deadline))
+			timeout = timer.C
+		}
+
+		var steps []*strategy.Step
+		select {
+		case b := <-s.in:
+			steps = s.next(b, time.Now())
+		case now := <-timeout:
+			steps = s.expire(now)
+		case <-ctx.Done():
+			return
+		}
+
+		for _, step := range steps {
+			release(step)
+		}
+	}
+}
+
+func (s *stepClock) deadline() (time.Time, bool) {
+	var min time.Time
+	for _, p := range s.pending {
+		if min.IsZero() || p.deadline.Before(min) {
+			min = p.deadline
+		}
+	}
+	return min, !min.IsZero()
+}
+
+// next add candle and return released steps
+// earlier steps of same level are released with missing codes
+func (s *stepClock) next(b stepBar, now time.Time) []*strategy.Step {
+	key := stepKey{end: b.end, level: b.info.level}
+	p, ok := s.pending[key]
+	if !ok {
+		p = &pendingStep{deadline: now.Add(s.grace)}
+		s.pending[key] = p
+	}
+	p.add(b)
+
+	return s.release(func(k stepKey, p *pendingStep) bool {
+		if k.level != key.level {
+			return false
+		}
+		return k.end.Before(key.end) || (k == key && len(p.bars) >= len(s.feeds[k.level]))
+	})
+}
+
+// expire release steps which grace is passed at now
+func (s *stepClock) expire(now time.Time) []*strategy.Step {
+	return s.release(func(k stepKey, p *pendingStep) bool {
+		return !p.deadline.After(now)
+	})
+}
+
+func (s *stepClock) release(done func(k stepKey, p *pendingStep) bool) []*strategy.Step {
+	var keys []stepKey
+	for k, p := range s.pending {
+		if done(k, p) {
+			keys = append(keys, k)
+		}
+	}
+	sortSteps(keys)
+
+	steps := make([]*strategy.Step, len(keys))
+	for i, k := range keys {
+		steps[i] = newStep(k, s.pending[k].bars, s.feeds[k.level], s.fill)
+		delete(s.pending, k)
+	}
+	return steps
+}
+
+// sortSteps sort steps by end time, and higher level first in same end time
+func sortSteps(keys []stepKey) {
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].end.Equal(keys[j].end) {
+			return keys[i].end.Before(keys[j].end)
+		}
+		return keys[i].level > keys[j].level
+	})
+}
+
+// newStep make step of bars, code of feeds without bar is missing and its container is filled by fill
+func newStep(key stepKey, bars []stepBar, feeds []feed, fill Fill) *strategy.Step {
+	step := &strategy.Step{Date: key.end, Level: key.level}
+	updated := make(map[string]bool)
+	for _, b := range bars {
+		step.Containers = append(step.Containers, b.con)
+		updated[b.con.Code()] = true
+	}
+
+	for _, f := range feeds {
+		code := f.con.Code()
+		if updated[code] {
+			continue
+		}
+		step.Missing = append(step.Missing, code)
+		fillContainer(f, key.end, fill)
+	}
+	return step
+}
+
+// fillContainer add candle of period ended at end to container which has no candle of it
+func fillContainer(f feed, end time.Time, fill Fill) {
+	if fill == FillNone || f.con.Empty() {
+		return
+	}
+
+	date := end
+	if f.info.LeftEdge {
+		date = end.Add(-f.info.level)
+	}
+	candle := container.Candle{Code: f.con.Code(), Date: date}
+	if fill == FillForward {
+		last := f.con.Values()[0]
+		candle.Open = last.Close
+		candle.High = last.Close
+		candle.Low = last.Close
+		candle.Close = last.Close
+	}
+	f.con.Add(candle)
+}
@@ -16,6 +16,8 @@
 package cerebro
 
 import (
+	"math"
+	"sort"
 	"time"
 
 	"github.com/gobenpark/trader/container"
@@ -23,52 +25,296 @@ import (
 
 type CompressInfo struct {
 	level    time.Duration
+	bar      container.BarSpec
 	LeftEdge bool
+	opts     []CompressionOption
 }
 
-func Compression(tick <-chan container.Tick, level time.Duration, leftEdge bool) <-chan container.Candle {
-	compressionDate := func(date time.Time) time.Time {
-		rd := date.Round(level)
-		if leftEdge {
-			if date.Sub(rd) < 0 {
-				rd = rd.Add(-level)
-			}
-		} else {
-			if date.Sub(rd) > 0 {
-				rd = rd.Add(level)
-			}
-		}
-		return rd
+// Fill decide candle of period which has no tick
+type Fill int
+
+const (
+	// FillNone skip period without tick
+	FillNone Fill = iota
+	// FillForward emit candle of previous close price with zero volume
+	FillForward
+	// FillEmpty emit candle which has only code and date
+	FillEmpty
+)
+
+type CompressionOption func(*compressor)
+
+// WithFill emit candles for periods without tick after first candle
+func WithFill(f Fill) CompressionOption {
+	return func(c *compressor) {
+		c.fill = f
+	}
+}
+
+// WithCloseDelay wait late ticks for d after period is finished before candle is closed by timer
+func WithCloseDelay(d time.Duration) CompressionOption {
+	return func(c *compressor) {
+		c.delay = d
 	}
+}
+
+// WithCompressionClock set current time of market which candle is closed by
+// default clock is time of latest tick plus elapsed time after it arrived
+// so replayed data is not closed by wall clock
+func WithCompressionClock(now func() time.Time) CompressionOption {
+	return func(c *compressor) {
+		c.clock = now
+	}
+}
+
+// WithSession align periods to session open of every day in loc, like daily candle from 09:00 KST
+// periods longer than a day are aligned to session open of monday
+func WithSession(loc *time.Location, open time.Duration) CompressionOption {
+	return func(c *compressor) {
+		c.loc = loc
+		c.open = open
+	}
+}
+
+// Compression make candles of level from ticks
+// candle of left edge is dated by start of period [start, start+level)
+// otherwise it is dated by end of period (end-level, end]
+// candle is closed when tick of next period arrives or period is finished by clock,
+// and last candle is flushed when tick channel is closed
+func Compression(tick <-chan container.Tick, level time.Duration, leftEdge bool, opts ...CompressionOption) <-chan container.Candle {
+	c := newCompressor(level, leftEdge, opts...)
 	ch := make(chan container.Candle, 1)
 	go func() {
 		defer close(ch)
-		c := container.Candle{}
-		for t := range tick {
-			if c.Date.Equal(time.Time{}) {
-				c.Date = compressionDate(t.Date)
+		runBars(tick, []barBuilder{c}, nil, func(closed [][]container.Candle) {
+			for _, candle := range closed[0] {
+				ch <- candle
 			}
-
-			if c.Date.Equal(compressionDate(t.Date)) {
-				c.Volume += t.Volume
-				c.Code = t.Code
-				c.Close = t.Price
-				if c.Open == 0 {
-					c.Open = t.Price
-				}
-
-				if c.High < t.Price {
-					c.High = t.Price
-				}
-
-				if c.Low == 0 || c.Low > t.Price {
-					c.Low = t.Price
-				}
-			} else {
-				ch <- c
-				c = container.Candle{}
-			}
-		}
+		})
 	}()
 	return ch
 }
+
+// compressor build candles from ticks
+type compressor struct {
+	level    time.Duration
+	leftEdge bool
+	fill     Fill
+	delay    time.Duration
+	clock    func() time.Time
+	// loc, open is session which periods are aligned to, periods are aligned to zero time if loc is nil
+	loc  *time.Location
+	open time.Duration
+	// bar is candle of current period, nil if there is no tick yet
+	bar *container.Candle
+	// last is latest emitted candle
+	last *container.Candle
+	// lastTick, lastWall are date of latest tick and wall time when it arrived
+	lastTick time.Time
+	lastWall time.Time
+}
+
+func newCompressor(level time.Duration, leftEdge bool, opts ...CompressionOption) *compressor {
+	c := &compressor{level: level, leftEdge: leftEdge}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// floor is start of period which date belongs to
+func (c *compressor) floor(date time.Time) time.Time {
+	if c.loc == nil {
+		return date.Truncate(c.level)
+	}
+
+	var anchor time.Time
+	if c.level > 24*time.Hour {
+		anchor = time.Date(1970, 1, 5, 0, 0, 0, 0, c.loc).Add(c.open)
+	} else {
+		y, m, d := date.In(c.loc).Date()
+		anchor = time.Date(y, m, d, 0, 0, 0, 0, c.loc).Add(c.open)
+		if date.Before(anchor) {
+			anchor = time.Date(y, m, d-1, 0, 0, 0, 0, c.loc).Add(c.open)
+		}
+	}
+
+	n := date.Sub(anchor) / c.level
+	if date.Before(anchor.Add(n * c.level)) {
+		n--
+	}
+	return anchor.Add(n * c.level)
+}
+
+// key is date of candle which date belongs to
+func (c *compressor) key(date time.Time) time.Time {
+	k := c.floor(date)
+	if !c.leftEdge && !k.Equal(date) {
+		k = k.Add(c.level)
+	}
+	return k
+}
+
+// end is time when period of candle is finished
+func (c *compressor) end(key time.Time) time.Time {
+	if c.leftEdge {
+		return key.Add(c.level)
+	}
+	return key
+}
+
+func (c *compressor) now() time.Time {
+	if c.clock != nil {
+		return c.clock()
+	}
+	if c.lastTick.IsZero() {
+		return time.Now()
+	}
+	return c.lastTick.Add(time.Since(c.lastWall))
+}
+
+// deadline is time when next candle should be closed without tick
+func (c *compressor) deadline() (time.Time, bool) {
+	switch {
+	case c.bar != nil:
+		return c.end(c.bar.Date).Add(c.delay), true
+	case c.fill != FillNone && c.last != nil:
+		return c.end(c.last.Date.Add(c.level)).Add(c.delay), true
+	}
+	return time.Time{}, false
+}
+
+func (c *compressor) wait() (time.Duration, bool) {
+	deadline, ok := c.deadline()
+	if !ok {
+		return 0, false
+	}
+	return deadline.Sub(c.now()), true
+}
+
+func (c *compressor) expire() []container.Candle {
+	return c.advance(c.now())
+}
+
+// add apply tick and return candles which are closed by it
+// tick of already closed period is added to next period
+func (c *compressor) add(t container.Tick) []container.Candle {
+	if t.Date.After(c.lastTick) {
+		c.lastTick = t.Date
+		c.lastWall = time.Now()
+	}
+
+	k := c.key(t.Date)
+	if c.last != nil && !k.After(c.last.Date) {
+		k = c.last.Date.Add(c.level)
+	}
+
+	var closed []container.Candle
+	if c.bar != nil && k.After(c.bar.Date) {
+		closed = append(closed, c.close())
+	}
+	closed = append(closed, c.fillUntil(k)...)
+
+	if c.bar == nil {
+		c.bar = &container.Candle{Code: t.Code, Date: k, Open: t.Price, High: t.Price, Low: t.Price}
+	}
+	c.bar.Close = t.Price
+	c.bar.Volume += t.Volume
+	if t.Price > c.bar.High {
+		c.bar.High = t.Price
+	}
+	if t.Price < c.bar.Low {
+		c.bar.Low = t.Price
+	}
+	return closed
+}
+
+// advance close candles which period is finished at now
+func (c *compressor) advance(now time.Time) []container.Candle {
+	var closed []container.Candle
+	if c.bar != nil {
+		if c.end(c.bar.Date).Add(c.delay).After(now) {
+			return nil
+		}
+		closed = append(closed, c.close())
+	}
+
+	if c.fill == FillNone || c.last == nil {
+		return closed
+	}
+	for !c.end(c.last.Date.Add(c.level)).Add(c.delay).After(now) {
+		closed = append(closed, c.filled(c.last.Date.Add(c.level)))
+	}
+	return closed
+}
+
+// flush close current candle even if period is not finished
+func (c *compressor) flush() []container.Candle {
+	if c.bar == nil {
+		return nil
+	}
+	return []container.Candle{c.close()}
+}
+
+func (c *compressor) close() container.Candle {
+	bar := *c.bar
+	c.last = &bar
+	c.bar = nil
+	return bar
+}
+
+// fillUntil emit filled candles of periods between last candle and key
+func (c *compressor) fillUntil(key time.Time) []container.Candle {
+	if c.fill == FillNone || c.last == nil {
+		return nil
+	}
+
+	var filled []container.Candle
+	for d := c.last.Date.Add(c.level); d.Before(key); d = d.Add(c.level) {
+		filled = append(filled, c.filled(d))
+	}
+	return filled
+}
+
+func (c *compressor) filled(date time.Time) container.Candle {
+	candle := container.Candle{Code: c.last.Code, Date: date}
+	if c.fill == FillForward {
+		candle.Open = c.last.Close
+		candle.High = c.last.Close
+		candle.Low = c.last.Close
+		candle.Close = c.last.Close
+	}
+	c.last = &candle
+	return candle
+}
+
+// Resample merge candles to candles of level, candles should be dated by same edge as leftEdge
+// open is first open, high is highest, low is lowest, close is last close and volume is sum in period
+// last period can be unfinished if candles do not reach end of it
+func Resample(candles []container.Candle, level time.Duration, leftEdge bool, opts ...CompressionOption) []container.Candle {
+	c := newCompressor(level, leftEdge, opts...)
+
+	sorted := make([]container.Candle, len(candles))
+	copy(sorted, candles)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Date.Before(sorted[j].Date)
+	})
+
+	var resampled []container.Candle
+	for _, candle := range sorted {
+		k := c.key(candle.Date)
+		n := len(resampled) - 1
+		if n < 0 || !resampled[n].Date.Equal(k) {
+			candle.Date = k
+			resampled = append(resampled, candle)
+			continue
+		}
+
+		r := &resampled[n]
+		r.High = math.Max(r.High, candle.High)
+		r.Low = math.Min(r.Low, candle.Low)
+		r.Close = candle.Close
+		r.Volume += candle.Volume
+	}
+	return resampled
+}
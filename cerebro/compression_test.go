@@ -102,6 +102,143 @@ func TestCompression(t *testing.T) {
 	for d := range Compression(ch, time.Minute*3, false) {
 		rightedge = append(rightedge, d)
 	}
-	assert.Len(t, rightedge, 2)
+	assert.Len(t, rightedge, 3)
+	assert.Equal(t, container.Candle{Code: "test", Date: ti1.Truncate(time.Minute).Add(3 * time.Minute), Open: 1, High: 4, Low: 1, Close: 4, Volume: 40}, rightedge[0])
+	assert.Equal(t, container.Candle{Code: "test", Date: ti1.Truncate(time.Minute).Add(6 * time.Minute), Open: 10, High: 10, Low: 5, Close: 6, Volume: 30}, rightedge[1])
+	assert.Equal(t, container.Candle{Code: "test", Date: ti1.Truncate(time.Minute).Add(9 * time.Minute), Open: 6, High: 6, Low: 6, Close: 6, Volume: 20}, rightedge[2])
+}
+
+func TestCompression_Boundary(t *testing.T) {
+	base := time.Date(2021, 3, 12, 0, 0, 0, 0, time.UTC)
+	input := []container.Tick{
+		{Code: "test", Date: base.Add(10 * time.Second), Price: 1, Volume: 1},
+		{Code: "test", Date: base.Add(time.Minute), Price: 2, Volume: 1},
+		{Code: "test", Date: base.Add(70 * time.Second), Price: 3, Volume: 1},
+	}
+
+	t.Run("left edge", func(t *testing.T) {
+		c := newCompressor(time.Minute, true)
+		assert.Empty(t, c.add(input[0]))
+		assert.Equal(t, []container.Candle{{Code: "test", Date: base, Open: 1, High: 1, Low: 1, Close: 1, Volume: 1}}, c.add(input[1]))
+		assert.Empty(t, c.add(input[2]))
+		assert.Equal(t, []container.Candle{{Code: "test", Date: base.Add(time.Minute), Open: 2, High: 3, Low: 2, Close: 3, Volume: 2}}, c.flush())
+	})
+
+	t.Run("right edge", func(t *testing.T) {
+		c := newCompressor(time.Minute, false)
+		assert.Empty(t, c.add(input[0]))
+		assert.Empty(t, c.add(input[1]))
+		assert.Equal(t, []container.Candle{{Code: "test", Date: base.Add(time.Minute), Open: 1, High: 2, Low: 1, Close: 2, Volume: 2}}, c.add(input[2]))
+		assert.Equal(t, []container.Candle{{Code: "test", Date: base.Add(2 * time.Minute), Open: 3, High: 3, Low: 3, Close: 3, Volume: 1}}, c.flush())
+	})
+}
+
+func TestCompression_Advance(t *testing.T) {
+	base := time.Date(2021, 3, 12, 0, 0, 0, 0, time.UTC)
+
+	t.Run("close by clock", func(t *testing.T) {
+		c := newCompressor(time.Minute, true, WithCloseDelay(time.Second))
+		c.add(container.Tick{Code: "test", Date: base.Add(10 * time.Second), Price: 1, Volume: 1})
+		assert.Empty(t, c.advance(base.Add(time.Minute)))
+		assert.Len(t, c.advance(base.Add(time.Minute+time.Second)), 1)
+		assert.Empty(t, c.advance(base.Add(5*time.Minute)))
+
+		// late tick of closed period goes to next candle
+		assert.Empty(t, c.add(container.Tick{Code: "test", Date: base.Add(50 * time.Second), Price: 2, Volume: 1}))
+		assert.Equal(t, []container.Candle{{Code: "test", Date: base.Add(time.Minute), Open: 2, High: 2, Low: 2, Close: 2, Volume: 1}}, c.flush())
+	})
+
+	t.Run("forward fill", func(t *testing.T) {
+		c := newCompressor(time.Minute, true, WithFill(FillForward))
+		c.add(container.Tick{Code: "test", Date: base.Add(10 * time.Second), Price: 1, Volume: 1})
+		candles := c.advance(base.Add(150 * time.Second))
+		assert.Equal(t, []container.Candle{
+			{Code: "test", Date: base, Open: 1, High: 1, Low: 1, Close: 1, Volume: 1},
+			{Code: "test", Date: base.Add(time.Minute), Open: 1, High: 1, Low: 1, Close: 1},
+		}, candles)
+
+		candles = c.add(container.Tick{Code: "test", Date: base.Add(4 * time.Minute), Price: 2, Volume: 1})
+		assert.Equal(t, []container.Candle{
+			{Code: "test", Date: base.Add(2 * time.Minute), Open: 1, High: 1, Low: 1, Close: 1},
+			{Code: "test", Date: base.Add(3 * time.Minute), Open: 1, High: 1, Low: 1, Close: 1},
+		}, candles)
+	})
+
+	t.Run("empty fill", func(t *testing.T) {
+		c := newCompressor(time.Minute, false, WithFill(FillEmpty))
+		c.add(container.Tick{Code: "test", Date: base.Add(10 * time.Second), Price: 1, Volume: 1})
+		candles := c.add(container.Tick{Code: "test", Date: base.Add(130 * time.Second), Price: 2, Volume: 1})
+		assert.Equal(t, []container.Candle{
+			{Code: "test", Date: base.Add(time.Minute), Open: 1, High: 1, Low: 1, Close: 1, Volume: 1},
+			{Code: "test", Date: base.Add(2 * time.Minute)},
+		}, candles)
+	})
+}
+
+func TestCompression_Timer(t *testing.T) {
+	ch := make(chan container.Tick)
+	defer close(ch)
+
+	// period is finished 50ms after tick by clock of ticks
+	date := time.Date(2021, 3, 12, 0, 0, 59, 950000000, time.UTC)
+	out := Compression(ch, time.Minute, true)
+	ch <- container.Tick{Code: "test", Date: date, Price: 1, Volume: 1}
+
+	select {
+	case candle := <-out:
+		assert.Equal(t, date.Truncate(time.Minute), candle.Date)
+	case <-time.After(time.Second):
+		t.Fatal("candle is not closed by timer")
+	}
+}
+
+func TestResample(t *testing.T) {
+	base := time.Date(2021, 3, 12, 0, 0, 0, 0, time.UTC)
+	var candles []container.Candle
+	for i := 0; i < 7; i++ {
+		candles = append(candles, container.Candle{
+			Code:   "test",
+			Open:   float64(i),
+			High:   float64(i) + 2,
+			Low:    float64(i) - 1,
+			Close:  float64(i) + 1,
+			Volume: 10,
+			Date:   base.Add(time.Duration(i) * time.Minute),
+		})
+	}
+	// order of history is not trusted
+	candles[0], candles[6] = candles[6], candles[0]
+
+	t.Run("left edge", func(t *testing.T) {
+		resampled := Resample(candles, 3*time.Minute, true)
+		assert.Equal(t, []container.Candle{
+			{Code: "test", Open: 0, High: 4, Low: -1, Close: 3, Volume: 30, Date: base},
+			{Code: "test", Open: 3, High: 7, Low: 2, Close: 6, Volume: 30, Date: base.Add(3 * time.Minute)},
+			{Code: "test", Open: 6, High: 8, Low: 5, Close: 7, Volume: 10, Date: base.Add(6 * time.Minute)},
+		}, resampled)
+	})
+
+	t.Run("right edge", func(t *testing.T) {
+		// candle of 00:00 is period (23:59, 00:00]
+		resampled := Resample(candles, 3*time.Minute, false)
+		assert.Len(t, resampled, 3)
+		assert.Equal(t, container.Candle{Code: "test", Open: 0, High: 2, Low: -1, Close: 1, Volume: 10, Date: base}, resampled[0])
+		assert.Equal(t, container.Candle{Code: "test", Open: 1, High: 5, Low: 0, Close: 4, Volume: 30, Date: base.Add(3 * time.Minute)}, resampled[1])
+	})
+
+	t.Run("session", func(t *testing.T) {
+		ny := time.FixedZone("EST", -5*3600)
+		hourly := []container.Candle{
+			{Code: "test", Open: 1, High: 1, Low: 1, Close: 1, Volume: 1, Date: time.Date(2021, 3, 12, 13, 0, 0, 0, time.UTC)},
+			{Code: "test", Open: 2, High: 2, Low: 2, Close: 2, Volume: 1, Date: time.Date(2021, 3, 12, 14, 30, 0, 0, time.UTC)},
+			{Code: "test", Open: 3, High: 3, Low: 3, Close: 3, Volume: 1, Date: time.Date(2021, 3, 13, 14, 0, 0, 0, time.UTC)},
+		}
 
+		// session opens at 09:30 EST, 14:30 UTC
+		daily := Resample(hourly, 24*time.Hour, true, WithSession(ny, 9*time.Hour+30*time.Minute))
+		assert.Equal(t, []container.Candle{
+			{Code: "test", Open: 1, High: 1, Low: 1, Close: 1, Volume: 1, Date: time.Date(2021, 3, 11, 9, 30, 0, 0, ny)},
+			{Code: "test", Open: 2, High: 3, Low: 2, Close: 3, Volume: 2, Date: time.Date(2021, 3, 12, 9, 30, 0, 0, ny)},
+		}, daily)
+	})
 }
@@ -0,0 +1,296 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package cerebro
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/gobenpark/trader/analysis"
+	error2 "github.com/gobenpark/trader/error"
+	"github.com/gobenpark/trader/store"
+	"github.com/gobenpark/trader/strategy"
+)
+
+// Params is one combination of strategy parameters
+type Params map[string]interface{}
+
+// Int return parameter as int, zero if it is not number
+func (p Params) Int(name string) int {
+	return int(p.Float(name))
+}
+
+// Float return parameter as float64, zero if it is not number
+func (p Params) Float(name string) float64 {
+	switch v := p[name].(type) {
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	}
+	return 0
+}
+
+func (p Params) String() string {
+	var s []string
+	for _, k := range p.keys() {
+		s = append(s, fmt.Sprintf("%s=%v", k, p[k]))
+	}
+	return strings.Join(s, " ")
+}
+
+func (p Params) keys() []string {
+	var keys []string
+	for k := range p {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ParamGrid is candidate values of each parameter
+type ParamGrid map[string][]interface{}
+
+// Combinations is every parameter set of grid, last parameter in order of name changes fastest
+func (g ParamGrid) Combinations() []Params {
+	var keys []string
+	for k := range g {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	combinations := []Params{{}}
+	for _, k := range keys {
+		var next []Params
+		for _, c := range combinations {
+			for _, v := range g[k] {
+				p := Params{k: v}
+				for ck, cv := range c {
+					p[ck] = cv
+				}
+				next = append(next, p)
+			}
+		}
+		combinations = next
+	}
+	return combinations
+}
+
+// OptimizeResult is analyzer results of one parameter set
+type OptimizeResult struct {
+	Params  Params
+	Results map[string]analysis.Result
+	Err     error
+}
+
+// Optimizer run independent backtest for every parameter set of grid in parallel
+// each run has own cerebro, so store, strategy and analyzers are created by factory for each run
+type Optimizer struct {
+	strategy  func(p Params) strategy.Strategy
+	grid      ParamGrid
+	store     func() store.Store
+	codes     []string
+	analyzers func() []analysis.Analyzer
+	options   []Option
+	workers   int
+	// rank analyzer name and result key which results are sorted by
+	rank [2]string
+	desc bool
+}
+
+type OptimizerOption func(*Optimizer)
+
+// WithOptimizeStore set store factory, simulator store should be created for each run
+// data store inside simulator can be shared when it is safe for concurrent use
+func WithOptimizeStore(s func() store.Store, codes ...string) OptimizerOption {
+	return func(o *Optimizer) {
+		o.store = s
+		o.codes = codes
+	}
+}
+
+func WithOptimizeAnalyzer(a func() []analysis.Analyzer) OptimizerOption {
+	return func(o *Optimizer) {
+		o.analyzers = a
+	}
+}
+
+// WithOptimizeOption set cerebro options applied to every run, WithBacktest is required
+func WithOptimizeOption(opts ...Option) OptimizerOption {
+	return func(o *Optimizer) {
+		o.options = append(o.options, opts...)
+	}
+}
+
+// WithWorkers limit count of parallel runs, default is count of cpu
+func WithWorkers(n int) OptimizerOption {
+	return func(o *Optimizer) {
+		o.workers = n
+	}
+}
+
+// WithRankBy sort results by key of analyzer result, descending if desc is true
+func WithRankBy(analyzer, key string, desc bool) OptimizerOption {
+	return func(o *Optimizer) {
+		o.rank = [2]string{analyzer, key}
+		o.desc = desc
+	}
+}
+
+func NewOptimizer(strategy func(p Params) strategy.Strategy, grid ParamGrid, opts ...OptimizerOption) *Optimizer {
+	o := &Optimizer{
+		strategy: strategy,
+		grid:     grid,
+		workers:  runtime.NumCPU(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Run backtest every parameter set and return results ranked by WithRankBy
+// failed runs are placed at the end with its error
+func (o *Optimizer) Run() (OptimizeResults, error) {
+	if o.store == nil {
+		return nil, error2.ErrStoreNotExists
+	}
+
+	combinations := o.grid.Combinations()
+	results := make(OptimizeResults, len(combinations))
+
+	workers := o.workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	jobs := make(chan int)
+	wg := sync.WaitGroup{}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := range jobs {
+				results[k] = o.run(combinations[k])
+			}
+		}()
+	}
+
+	for k := range combinations {
+		jobs <- k
+	}
+	close(jobs)
+	wg.Wait()
+
+	if o.rank[0] != "" {
+		sort.SliceStable(results, func(i, j int) bool {
+			return o.less(results[i], results[j])
+		})
+	}
+	return results, nil
+}
+
+func (o *Optimizer) run(p Params) OptimizeResult {
+	c := o.cerebro(p)
+	if !c.backtest {
+		return OptimizeResult{Params: p, Err: error2.ErrNotBacktest}
+	}
+	if err := c.Start(); err != nil {
+		return OptimizeResult{Params: p, Err: err}
+	}
+	return OptimizeResult{Params: p, Results: c.Results()}
+}
+
+// cerebro create new cerebro of parameter set, opts are applied after options of optimizer
+func (o *Optimizer) cerebro(p Params, opts ...Option) *Cerebro {
+	options := append([]Option{}, o.options...)
+	options = append(options, WithStore(o.store(), o.codes...), WithStrategy(o.strategy(p)))
+	if o.analyzers != nil {
+		options = append(options, WithAnalyzer(o.analyzers()...))
+	}
+	return NewCerebro(append(options, opts...)...)
+}
+
+// less is true if a is ranked higher than b
+func (o *Optimizer) less(a, b OptimizeResult) bool {
+	if a.Err != nil || b.Err != nil {
+		return a.Err == nil && b.Err != nil
+	}
+
+	av, bv := a.Results[o.rank[0]][o.rank[1]], b.Results[o.rank[0]][o.rank[1]]
+	if math.IsNaN(bv) {
+		return !math.IsNaN(av)
+	}
+	if o.desc {
+		return av > bv
+	}
+	return av < bv
+}
+
+// OptimizeResults is ranked results of optimizer
+type OptimizeResults []OptimizeResult
+
+// Table write results as aligned text table
+// columns are rank, parameters and every analyzer result in order of name
+func (r OptimizeResults) Table(w io.Writer) error {
+	var params, columns []string
+	seen := make(map[string]bool)
+	for _, i := range r {
+		for _, k := range i.Params.keys() {
+			if !seen["param:"+k] {
+				seen["param:"+k] = true
+				params = append(params, k)
+			}
+		}
+		for name, result := range i.Results {
+			for key := range result {
+				if col := name + "." + key; !seen[col] {
+					seen[col] = true
+					columns = append(columns, col)
+				}
+			}
+		}
+	}
+	sort.Strings(params)
+	sort.Strings(columns)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(append(append([]string{"rank"}, params...), columns...), "\t"))
+	for k, i := range r {
+		row := []string{fmt.Sprint(k + 1)}
+		for _, p := range params {
+			row = append(row, fmt.Sprint(i.Params[p]))
+		}
+		if i.Err != nil {
+			row = append(row, i.Err.Error())
+		} else {
+			for _, col := range columns {
+				name := strings.SplitN(col, ".", 2)
+				row = append(row, fmt.Sprintf("%.4f", i.Results[name[0]][name[1]]))
+			}
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
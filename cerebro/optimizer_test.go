@@ -0,0 +1,109 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package cerebro
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gobenpark/trader/analysis"
+	"github.com/gobenpark/trader/broker"
+	"github.com/gobenpark/trader/container"
+	error2 "github.com/gobenpark/trader/error"
+	"github.com/gobenpark/trader/order"
+	"github.com/gobenpark/trader/store"
+	"github.com/gobenpark/trader/store/simulator"
+	"github.com/gobenpark/trader/strategy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// paramStrategy buy size at bar of index at
+type paramStrategy struct {
+	recordStrategy
+	at   int
+	size int64
+}
+
+func (p *paramStrategy) Next(broker *broker.Broker, container container.Container) {
+	if len(p.candles) == p.at {
+		broker.Buy(container.Code(), p.size, 0, order.Market)
+	}
+	p.recordStrategy.Next(broker, container)
+}
+
+func TestParamGrid_Combinations(t *testing.T) {
+	c := ParamGrid{"b": {1, 2}, "a": {0.5, 1.5}, "c": {"x"}}.Combinations()
+	assert.Len(t, c, 4)
+	assert.Equal(t, "a=0.5 b=1 c=x", c[0].String())
+	assert.Equal(t, "a=0.5 b=2 c=x", c[1].String())
+	assert.Equal(t, "a=1.5 b=2 c=x", c[3].String())
+	assert.Equal(t, 1, c[3].Int("a"))
+	assert.Equal(t, float64(2), c[3].Float("b"))
+}
+
+func TestOptimizer_Run(t *testing.T) {
+	start := time.Date(2021, 3, 12, 0, 0, 0, 0, time.UTC)
+	data := HistoryStore{start: start}
+
+	o := NewOptimizer(
+		func(p Params) strategy.Strategy {
+			return &paramStrategy{at: p.Int("at"), size: int64(p.Int("size"))}
+		},
+		ParamGrid{"at": {1, 2, 3}, "size": {10, 20}},
+		WithOptimizeStore(func() store.Store {
+			return simulator.NewStore(data, 1000, 0)
+		}, "test1"),
+		WithOptimizeAnalyzer(func() []analysis.Analyzer {
+			return []analysis.Analyzer{analysis.NewReturns(), analysis.NewDrawDown()}
+		}),
+		WithOptimizeOption(WithResample("test1", time.Minute, true), WithBacktest(start, time.Time{})),
+		WithWorkers(3),
+		WithRankBy("returns", "total", true),
+	)
+
+	results, err := o.Run()
+	require.NoError(t, err)
+	require.Len(t, results, 6)
+
+	// buying at index at is filled with open of next bar and price rise to 9
+	assert.Equal(t, Params{"at": 1, "size": 20}, results[0].Params)
+	assert.InDelta(t, 0.14, results[0].Results["returns"]["total"], 1e-9)
+	assert.Equal(t, Params{"at": 3, "size": 10}, results[5].Params)
+	assert.InDelta(t, 0.05, results[5].Results["returns"]["total"], 1e-9)
+
+	var buf bytes.Buffer
+	require.NoError(t, results.Table(&buf))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 7)
+	assert.Equal(t, []string{"rank", "at", "size", "drawdown.max_drawdown", "drawdown.max_drawdown_duration",
+		"drawdown.max_drawdown_len", "drawdown.max_drawdown_money", "returns.cagr", "returns.total"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"1", "1", "20"}, strings.Fields(lines[1])[:3])
+}
+
+func TestOptimizer_NotBacktest(t *testing.T) {
+	o := NewOptimizer(
+		func(p Params) strategy.Strategy { return &recordStrategy{} },
+		ParamGrid{"a": {1}},
+		WithOptimizeStore(func() store.Store { return simulator.NewStore(SampleStore{}, 1000, 0) }, "test1"),
+	)
+
+	results, err := o.Run()
+	require.NoError(t, err)
+	assert.Equal(t, error2.ErrNotBacktest, results[0].Err)
+}
@@ -18,6 +18,8 @@ package cerebro
 import (
 	"time"
 
+	"github.com/gobenpark/trader/analysis"
+	"github.com/gobenpark/trader/container"
 	"github.com/gobenpark/trader/observer"
 	"github.com/gobenpark/trader/store"
 	"github.com/gobenpark/trader/strategy"
@@ -43,12 +45,27 @@ func WithObserver(o observer.Observer) Option {
 	}
 }
 
+// WithRecorder record live ticks and candles of every compression level to files
+func WithRecorder(r *observer.Recorder) Option {
+	return func(c *Cerebro) {
+		c.recorder = r
+	}
+}
+
 func WithStrategy(s ...strategy.Strategy) Option {
 	return func(c *Cerebro) {
 		c.strategyEngine.Sts = s
 	}
 }
 
+// WithAnalyzer add analyzers which observe bars and events during run
+// results are given by Cerebro.Results after run
+func WithAnalyzer(a ...analysis.Analyzer) Option {
+	return func(c *Cerebro) {
+		c.analysisEngine.Analyzers = append(c.analysisEngine.Analyzers, a...)
+	}
+}
+
 func WithStore(s store.Store, initCodes ...string) Option {
 	return func(c *Cerebro) {
 		c.store = s
@@ -62,9 +79,45 @@ func WithLive(isLive bool) Option {
 	}
 }
 
-func WithResample(code string, level time.Duration, leftEdge bool) Option {
+// WithResample compress ticks of code to candles of level, opts decide how candle is closed and filled
+// level which is not positive is skipped
+func WithResample(code string, level time.Duration, leftEdge bool, opts ...CompressionOption) Option {
+	return func(c *Cerebro) {
+		if !container.TimeBar(level).Valid() {
+			GetLogger().Warningf("%s bar of %s is not valid, skip it", container.TimeBar(level), code)
+			return
+		}
+		c.compress[code] = append(c.compress[code], CompressInfo{level: level, bar: container.TimeBar(level), LeftEdge: leftEdge, opts: opts})
+	}
+}
+
+// WithBar compress ticks of code to candles of spec like tick, volume, dollar, renko and range bar
+// these bars are made only from live ticks, so they are not preloaded or replayed in backtest
+// spec which is not valid is skipped
+func WithBar(code string, spec container.BarSpec) Option {
 	return func(c *Cerebro) {
-		c.compress[code] = append(c.compress[code], CompressInfo{level: level, LeftEdge: leftEdge})
+		if !spec.Valid() {
+			GetLogger().Warningf("%s bar of %s is not valid, skip it", spec, code)
+			return
+		}
+		c.compress[code] = append(c.compress[code], CompressInfo{level: spec.Level, bar: spec})
+	}
+}
+
+// WithStepClock align candles of every code by time and deliver them to strategies as one step
+// live step waits candles of other codes for grace, and container of code without candle is filled by fill
+func WithStepClock(fill Fill, grace time.Duration) Option {
+	return func(c *Cerebro) {
+		c.clock = newStepClock(fill, grace)
+	}
+}
+
+// WithHistoryLevel load history of level from store and resample it to every level of codes
+// leftEdge is whether candles of store are dated by start of period
+func WithHistoryLevel(level time.Duration, leftEdge bool) Option {
+	return func(c *Cerebro) {
+		c.historyLevel = level
+		c.historyLeftEdge = leftEdge
 	}
 }
 
@@ -73,3 +126,14 @@ func WithPreload(b bool) Option {
 		c.preload = b
 	}
 }
+
+// WithBacktest replay candle history between from and to through strategies
+// candles before from are used to warm up container, zero to is unlimited
+// Start returns when history data is exhausted
+func WithBacktest(from, to time.Time) Option {
+	return func(c *Cerebro) {
+		c.backtest = true
+		c.from = from
+		c.to = to
+	}
+}
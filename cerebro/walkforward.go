@@ -0,0 +1,158 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package cerebro
+
+import (
+	"time"
+
+	"github.com/gobenpark/trader/analysis"
+	"github.com/gobenpark/trader/strategy"
+)
+
+// Window is one step of walk forward
+// Params is best parameter set of in sample period, and it is tested on following out of sample period
+type Window struct {
+	InFrom    time.Time
+	InTo      time.Time
+	OutFrom   time.Time
+	OutTo     time.Time
+	Params    Params
+	InSample  map[string]analysis.Result
+	OutSample map[string]analysis.Result
+}
+
+// WalkForwardResult is every window and out of sample equity curve stitched together
+// equity of each window is scaled to start from last equity of previous window
+type WalkForwardResult struct {
+	Windows []Window
+	Equity  []analysis.Point
+}
+
+// WalkForward optimize parameters on rolling in sample window and test them on next out of sample window
+// windows move forward by out of sample length until the end of range
+type WalkForward struct {
+	optimizer *Optimizer
+	from      time.Time
+	to        time.Time
+	inSample  time.Duration
+	outSample time.Duration
+}
+
+// NewWalkForward create walk forward of range from to, options are same with optimizer
+// WithRankBy decide best parameter set, without it first combination of grid is chosen
+// backtest range of cerebro options is replaced with each window
+func NewWalkForward(strategy func(p Params) strategy.Strategy, grid ParamGrid, from, to time.Time, inSample, outSample time.Duration, opts ...OptimizerOption) *WalkForward {
+	return &WalkForward{
+		optimizer: NewOptimizer(strategy, grid, opts...),
+		from:      from,
+		to:        to,
+		inSample:  inSample,
+		outSample: outSample,
+	}
+}
+
+// Windows split range to in sample and out of sample periods
+func (w *WalkForward) Windows() []Window {
+	var windows []Window
+	if w.inSample <= 0 || w.outSample <= 0 {
+		return nil
+	}
+
+	for start := w.from; ; start = start.Add(w.outSample) {
+		in := start.Add(w.inSample)
+		if !in.Before(w.to) {
+			break
+		}
+		out := in.Add(w.outSample)
+		if out.After(w.to) {
+			out = w.to
+		}
+		windows = append(windows, Window{InFrom: start, InTo: in, OutFrom: in, OutTo: out})
+	}
+	return windows
+}
+
+func (w *WalkForward) Run() (*WalkForwardResult, error) {
+	result := &WalkForwardResult{}
+	for _, window := range w.Windows() {
+		o := *w.optimizer
+		o.options = append(append([]Option{}, o.options...), WithBacktest(window.InFrom, window.InTo.Add(-time.Nanosecond)))
+		ranked, err := o.Run()
+		if err != nil {
+			return nil, err
+		}
+		if len(ranked) == 0 {
+			break
+		}
+		if ranked[0].Err != nil {
+			return nil, ranked[0].Err
+		}
+		window.Params = ranked[0].Params
+		window.InSample = ranked[0].Results
+
+		equity := analysis.NewEquity()
+		c := w.optimizer.cerebro(window.Params, WithAnalyzer(equity), WithBacktest(window.OutFrom, window.OutTo.Add(-time.Nanosecond)))
+		if err := c.Start(); err != nil {
+			return nil, err
+		}
+		window.OutSample = c.Results()
+		delete(window.OutSample, equity.Name())
+
+		result.Windows = append(result.Windows, window)
+		result.Equity = stitch(result.Equity, equity.Curve())
+	}
+	return result, nil
+}
+
+// stitch append curve scaled from last equity of stitched curve, and drawdown is calculated again
+func stitch(stitched, curve []analysis.Point) []analysis.Point {
+	if len(curve) == 0 {
+		return stitched
+	}
+
+	scale := 1.0
+	if n := len(stitched); n > 0 && curve[0].Equity != 0 {
+		scale = stitched[n-1].Equity / curve[0].Equity
+	}
+
+	peak := 0.0
+	for _, p := range stitched {
+		if p.Equity > peak {
+			peak = p.Equity
+		}
+	}
+
+	for _, p := range curve {
+		p.Cash *= scale
+		p.PositionValue *= scale
+		p.Equity *= scale
+		positions := make(map[string]float64)
+		for code, v := range p.Positions {
+			positions[code] = v * scale
+		}
+		p.Positions = positions
+
+		if p.Equity > peak {
+			peak = p.Equity
+		}
+		p.DrawDown = 0
+		if peak > 0 {
+			p.DrawDown = (peak - p.Equity) / peak
+		}
+		stitched = append(stitched, p)
+	}
+	return stitched
+}
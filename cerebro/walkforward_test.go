@@ -0,0 +1,71 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package cerebro
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gobenpark/trader/analysis"
+	"github.com/gobenpark/trader/store"
+	"github.com/gobenpark/trader/store/simulator"
+	"github.com/gobenpark/trader/strategy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalkForward_Run(t *testing.T) {
+	start := time.Date(2021, 3, 12, 0, 0, 0, 0, time.UTC)
+	data := HistoryStore{start: start}
+
+	w := NewWalkForward(
+		func(p Params) strategy.Strategy {
+			return &paramStrategy{at: p.Int("at"), size: 10}
+		},
+		ParamGrid{"at": {1, 0}},
+		start, start.Add(10*time.Minute), 4*time.Minute, 3*time.Minute,
+		WithOptimizeStore(func() store.Store {
+			return simulator.NewStore(data, 1000, 0)
+		}, "test1"),
+		WithOptimizeAnalyzer(func() []analysis.Analyzer {
+			return []analysis.Analyzer{analysis.NewReturns()}
+		}),
+		WithOptimizeOption(WithResample("test1", time.Minute, true)),
+		WithRankBy("returns", "total", true),
+	)
+
+	windows := w.Windows()
+	require.Len(t, windows, 2)
+	assert.Equal(t, start.Add(3*time.Minute), windows[1].InFrom)
+	assert.Equal(t, start.Add(7*time.Minute), windows[1].OutFrom)
+	assert.Equal(t, start.Add(10*time.Minute), windows[1].OutTo)
+
+	result, err := w.Run()
+	require.NoError(t, err)
+	require.Len(t, result.Windows, 2)
+	for _, window := range result.Windows {
+		assert.Equal(t, Params{"at": 0}, window.Params)
+		assert.InDelta(t, 0.01, window.OutSample["returns"]["total"], 1e-9)
+	}
+
+	var equity []float64
+	for _, p := range result.Equity {
+		equity = append(equity, p.Equity)
+	}
+	require.Len(t, equity, 6)
+	assert.InDeltaSlice(t, []float64{1000, 1000, 1010, 1010, 1010, 1020.1}, equity, 1e-9)
+	assert.Equal(t, start.Add(9*time.Minute), result.Equity[5].Date)
+}
@@ -125,8 +125,9 @@ func (t *TraderChart) Start() {
 	}()
 
 	go func() {
-		http.HandleFunc("/", t.handler)
-		if err := http.ListenAndServe(":8081", nil); err != nil {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/", t.handler)
+		if err := http.ListenAndServe(":8081", mux); err != nil {
 			fmt.Println(err)
 		}
 	}()
@@ -33,7 +33,7 @@ type bgContainer struct {
 
 func NewBadgerContainer(db *badger.DB, info Info) Container {
 	b := &bgContainer{}
-	b.Info = info
+	b.Info = info.withBar()
 	b.DB = db
 	return b
 }
@@ -159,5 +159,9 @@ func (b *bgContainer) Add(candle Candle) {
 			return
 		}
 	}
-	txn.Commit()
+	if err := txn.Commit(); err != nil {
+		fmt.Println(err)
+		return
+	}
+	b.notify(candle)
 }
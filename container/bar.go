@@ -0,0 +1,109 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package container
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// BarType is rule closing candle of container
+type BarType int
+
+const (
+	// BarTime close candle every time level
+	BarTime BarType = iota
+	// BarTick close candle every number of ticks
+	BarTick
+	// BarVolume close candle when traded volume reaches size
+	BarVolume
+	// BarDollar close candle when traded notional (price*volume) reaches size
+	BarDollar
+	// BarRenko make brick whenever price moves size from previous brick
+	BarRenko
+	// BarRange close candle when high-low reaches size
+	BarRange
+)
+
+func (b BarType) String() string {
+	switch b {
+	case BarTime:
+		return "time"
+	case BarTick:
+		return "tick"
+	case BarVolume:
+		return "volume"
+	case BarDollar:
+		return "dollar"
+	case BarRenko:
+		return "renko"
+	case BarRange:
+		return "range"
+	}
+	return "unknown"
+}
+
+// BarSpec is specification of candles in container
+// Level is used for time bar and Size is used for the others
+type BarSpec struct {
+	Type  BarType
+	Level time.Duration
+	Size  float64
+}
+
+func TimeBar(level time.Duration) BarSpec {
+	return BarSpec{Type: BarTime, Level: level}
+}
+
+func TickBar(count int) BarSpec {
+	return BarSpec{Type: BarTick, Size: float64(count)}
+}
+
+func VolumeBar(volume float64) BarSpec {
+	return BarSpec{Type: BarVolume, Size: volume}
+}
+
+func DollarBar(notional float64) BarSpec {
+	return BarSpec{Type: BarDollar, Size: notional}
+}
+
+func RenkoBar(brick float64) BarSpec {
+	return BarSpec{Type: BarRenko, Size: brick}
+}
+
+func RangeBar(size float64) BarSpec {
+	return BarSpec{Type: BarRange, Size: size}
+}
+
+// String is level of time bar like 1m0s, and type-size like volume-1000 for the others
+func (b BarSpec) String() string {
+	if b.Type == BarTime {
+		return b.Level.String()
+	}
+	return fmt.Sprintf("%s-%s", b.Type, strconv.FormatFloat(b.Size, 'f', -1, 64))
+}
+
+// Valid is whether candles can be made by spec, time bar needs positive level and the others need positive size
+func (b BarSpec) Valid() bool {
+	switch b.Type {
+	case BarTime:
+		return b.Level > 0
+	case BarTick, BarVolume, BarDollar, BarRenko, BarRange:
+		return b.Size > 0
+	}
+	return false
+}
@@ -30,6 +30,14 @@ type Container interface {
 	Add(candle Candle)
 	Code() string
 	Level() time.Duration
+	Bar() BarSpec
+	// Register add listeners which are updated with every candle added after it
+	Register(listeners ...Listener)
+}
+
+// Listener is updated with candle added to container, like indicator
+type Listener interface {
+	Update(candle Candle)
 }
 
 type SaveMode int
@@ -42,19 +50,30 @@ const (
 type Info struct {
 	Code             string
 	CompressionLevel time.Duration
+	// Bar is spec of candles, time bar of CompressionLevel if it is empty
+	Bar BarSpec
 }
 
 //TODO: inmemory or external storage
 type DataContainer struct {
 	mu         sync.RWMutex
 	CandleData []Candle
+	listeners  []Listener
 	Info
 }
 
+// withBar fill time bar of CompressionLevel
+func (i Info) withBar() Info {
+	if i.Bar.Type == BarTime {
+		i.Bar.Level = i.CompressionLevel
+	}
+	return i
+}
+
 func NewDataContainer(info Info) *DataContainer {
 	return &DataContainer{
 		CandleData: []Candle{},
-		Info:       info,
+		Info:       info.withBar(),
 	}
 }
 
@@ -84,8 +103,9 @@ func (t *DataContainer) Values() []Candle {
 
 // Add foreword append container candle data
 // current candle [0] index
+// candle of same date is ignored for time bar, other bars can close several candles at same time
 func (t *DataContainer) Add(candle Candle) {
-	if len(t.CandleData) != 0 {
+	if len(t.CandleData) != 0 && t.Info.Bar.Type == BarTime {
 		for _, i := range t.CandleData {
 			if i.Date.Equal(candle.Date) {
 				return
@@ -95,6 +115,22 @@ func (t *DataContainer) Add(candle Candle) {
 	t.mu.Lock()
 	t.CandleData = append([]Candle{candle}, t.CandleData...)
 	t.mu.Unlock()
+	t.notify(candle)
+}
+
+func (t *DataContainer) Register(listeners ...Listener) {
+	t.mu.Lock()
+	t.listeners = append(t.listeners, listeners...)
+	t.mu.Unlock()
+}
+
+func (t *DataContainer) notify(candle Candle) {
+	t.mu.RLock()
+	listeners := t.listeners
+	t.mu.RUnlock()
+	for _, l := range listeners {
+		l.Update(candle)
+	}
 }
 
 func (t *DataContainer) Code() string {
@@ -104,3 +140,7 @@ func (t *DataContainer) Code() string {
 func (t *DataContainer) Level() time.Duration {
 	return t.Info.CompressionLevel
 }
+
+func (t *DataContainer) Bar() BarSpec {
+	return t.Info.Bar
+}
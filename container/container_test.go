@@ -72,3 +72,32 @@ func BenchmarkContainer(b *testing.B) {
 		})
 	}
 }
+
+func TestDataContainer_Bar(t *testing.T) {
+	c := NewDataContainer(Info{Code: "code", CompressionLevel: time.Minute})
+	assert.Equal(t, TimeBar(time.Minute), c.Bar())
+	assert.Equal(t, "1m0s", c.Bar().String())
+
+	date := time.Date(2021, 3, 12, 0, 0, 0, 0, time.UTC)
+	c.Add(Candle{Code: "code", Close: 1, Date: date})
+	c.Add(Candle{Code: "code", Close: 2, Date: date})
+	assert.Equal(t, 1, c.Size())
+
+	v := NewDataContainer(Info{Code: "code", Bar: VolumeBar(1000)})
+	assert.Equal(t, "volume-1000", v.Bar().String())
+	assert.Equal(t, time.Duration(0), v.Level())
+	v.Add(Candle{Code: "code", Close: 1, Date: date})
+	v.Add(Candle{Code: "code", Close: 2, Date: date})
+	assert.Equal(t, 2, v.Size())
+}
+
+func TestBarSpec_Valid(t *testing.T) {
+	assert.True(t, TimeBar(time.Minute).Valid())
+	assert.True(t, TickBar(10).Valid())
+	assert.True(t, RenkoBar(0.5).Valid())
+	assert.False(t, TimeBar(0).Valid())
+	assert.False(t, VolumeBar(-1).Valid())
+	assert.False(t, RangeBar(0).Valid())
+	assert.False(t, BarSpec{Type: BarType(100), Size: 1}.Valid())
+	assert.Equal(t, "unknown-1", BarSpec{Type: BarType(100), Size: 1}.String())
+}
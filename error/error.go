@@ -13,4 +13,9 @@ var (
 	ErrUnexpected     = Error{Code: 1, Message: "raise unexpected error"}
 	ErrStoreNotExists = Error{Code: 2, Message: "store not in cerebro"}
 	ErrNotExistCode   = Error{Code: 3, Message: "does not exist code"}
+	ErrNotSupportExec = Error{Code: 4, Message: "does not support execution type"}
+	ErrNotExistOrder  = Error{Code: 5, Message: "does not exist order"}
+	ErrNotBacktest    = Error{Code: 6, Message: "optimizer run only backtest"}
+	ErrNotExistData   = Error{Code: 7, Message: "does not exist data"}
+	ErrNotExistPrice  = Error{Code: 8, Message: "does not exist execution price"}
 )
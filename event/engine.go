@@ -18,43 +18,175 @@ package event
 
 import (
 	"context"
+	"sync"
 )
 
 type Engine struct {
 	broadcast  chan interface{}
 	Register   chan Listener
 	Unregister chan Listener
-	childEvent map[Listener]bool
+	childEvent map[Listener]*queue
+	done       <-chan struct{}
+
+	// pending count of event which is not yet delivered to every listener
+	mu      sync.Mutex
+	idle    *sync.Cond
+	pending int
+	stopped bool
 }
 
 func NewEventEngine() *Engine {
-	return &Engine{
+	e := &Engine{
 		broadcast:  make(chan interface{}, 10),
 		Register:   make(chan Listener),
 		Unregister: make(chan Listener),
-		childEvent: make(map[Listener]bool),
+		childEvent: make(map[Listener]*queue),
 	}
+	e.idle = sync.NewCond(&e.mu)
+	return e
 }
 
 func (e *Engine) Start(ctx context.Context) {
+	e.done = ctx.Done()
 	go func() {
+		defer e.stop()
 		for {
 			select {
 			case <-ctx.Done():
-				break
+				return
 			case evt := <-e.broadcast:
-				for c := range e.childEvent {
-					go c.Listen(evt)
+				for _, q := range e.childEvent {
+					e.add()
+					q.push(evt)
 				}
+				e.release()
 			case cli := <-e.Register:
-				e.childEvent[cli] = true
+				q := newQueue()
+				e.childEvent[cli] = q
+				go e.deliver(ctx, cli, q)
 			case cli := <-e.Unregister:
-				delete(e.childEvent, cli)
+				if q, ok := e.childEvent[cli]; ok {
+					for i := q.close(); i > 0; i-- {
+						e.release()
+					}
+					delete(e.childEvent, cli)
+				}
 			}
 		}
 	}()
 }
 
+// deliver call listener with queued events one by one
+// so every listener receive events in order of broadcast
+func (e *Engine) deliver(ctx context.Context, l Listener, q *queue) {
+	for {
+		evt, ok := q.pop(ctx)
+		if !ok {
+			return
+		}
+		l.Listen(evt)
+		e.release()
+	}
+}
+
 func (e *Engine) BroadCast(evt interface{}) {
-	e.broadcast <- evt
+	e.add()
+	select {
+	case e.broadcast <- evt:
+	case <-e.done:
+		e.release()
+	}
+}
+
+// Wait block until every broadcast event is delivered to listeners
+// including events which listeners broadcast while handling
+func (e *Engine) Wait() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for e.pending > 0 && !e.stopped {
+		e.idle.Wait()
+	}
+}
+
+func (e *Engine) add() {
+	e.mu.Lock()
+	e.pending++
+	e.mu.Unlock()
+}
+
+func (e *Engine) release() {
+	e.mu.Lock()
+	e.pending--
+	if e.pending <= 0 {
+		e.idle.Broadcast()
+	}
+	e.mu.Unlock()
+}
+
+func (e *Engine) stop() {
+	e.mu.Lock()
+	e.stopped = true
+	e.idle.Broadcast()
+	e.mu.Unlock()
+}
+
+// queue is unbounded event queue of listener
+// broadcasting never blocks even if listener broadcast while handling event
+type queue struct {
+	mu     sync.Mutex
+	events []interface{}
+	signal chan struct{}
+	closed bool
+}
+
+func newQueue() *queue {
+	return &queue{signal: make(chan struct{}, 1)}
+}
+
+func (q *queue) push(evt interface{}) {
+	q.mu.Lock()
+	q.events = append(q.events, evt)
+	q.mu.Unlock()
+	q.notify()
+}
+
+// close stop queue and return count of dropped events
+func (q *queue) close() int {
+	q.mu.Lock()
+	dropped := len(q.events)
+	q.events = nil
+	q.closed = true
+	q.mu.Unlock()
+	q.notify()
+	return dropped
+}
+
+func (q *queue) notify() {
+	select {
+	case q.signal <- struct{}{}:
+	default:
+	}
+}
+
+func (q *queue) pop(ctx context.Context) (interface{}, bool) {
+	for {
+		q.mu.Lock()
+		if len(q.events) > 0 {
+			evt := q.events[0]
+			q.events = q.events[1:]
+			q.mu.Unlock()
+			return evt, true
+		}
+		closed := q.closed
+		q.mu.Unlock()
+		if closed {
+			return nil, false
+		}
+
+		select {
+		case <-q.signal:
+		case <-ctx.Done():
+			return nil, false
+		}
+	}
 }
@@ -0,0 +1,161 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package event
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder record events, and broadcast next number while number is less than until
+type recorder struct {
+	mu     sync.Mutex
+	events []interface{}
+	engine *Engine
+	until  int
+}
+
+func (r *recorder) Listen(e interface{}) {
+	r.mu.Lock()
+	r.events = append(r.events, e)
+	r.mu.Unlock()
+	if n, ok := e.(int); ok && n < r.until {
+		r.engine.BroadCast(n + 1)
+	}
+}
+
+func (r *recorder) received() []interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]interface{}(nil), r.events...)
+}
+
+func start(t *testing.T, listeners ...Listener) (*Engine, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	e := NewEventEngine()
+	e.Start(ctx)
+	for _, l := range listeners {
+		e.Register <- l
+	}
+	return e, cancel
+}
+
+func TestEngine_Order(t *testing.T) {
+	first, second := &recorder{}, &recorder{}
+	e, _ := start(t, first, second)
+
+	var expected []interface{}
+	for i := 0; i < 100; i++ {
+		e.BroadCast(i)
+		expected = append(expected, i)
+	}
+	e.Wait()
+
+	// every listener receive events in order of broadcast
+	assert.Equal(t, expected, first.received())
+	assert.Equal(t, expected, second.received())
+}
+
+func TestEngine_WaitNested(t *testing.T) {
+	r := &recorder{until: 5}
+	e, _ := start(t, r)
+	r.engine = e
+
+	// events which are broadcast while handling event are waited too
+	e.BroadCast(0)
+	e.Wait()
+	assert.Equal(t, []interface{}{0, 1, 2, 3, 4, 5}, r.received())
+}
+
+// blocker block every event until release is closed
+type blocker struct {
+	entered chan interface{}
+	release chan struct{}
+}
+
+func newBlocker() *blocker {
+	return &blocker{entered: make(chan interface{}, 10), release: make(chan struct{})}
+}
+
+func (b *blocker) Listen(e interface{}) {
+	b.entered <- e
+	<-b.release
+}
+
+func TestEngine_WaitBlocked(t *testing.T) {
+	b := newBlocker()
+	e, _ := start(t, b)
+	e.BroadCast(1)
+
+	done := make(chan struct{})
+	go func() {
+		e.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("wait return before event is handled")
+	case <-time.After(time.Millisecond * 50):
+	}
+
+	close(b.release)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("wait does not return after event is handled")
+	}
+}
+
+func TestEngine_WaitStopped(t *testing.T) {
+	b := newBlocker()
+	defer close(b.release)
+	e, cancel := start(t, b)
+	e.BroadCast(1)
+
+	// stopped engine does not deliver events any more, so wait does not block
+	cancel()
+	done := make(chan struct{})
+	go func() {
+		e.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("wait block after engine is stopped")
+	}
+}
+
+func TestEngine_Unregister(t *testing.T) {
+	b := newBlocker()
+	r := &recorder{}
+	e, _ := start(t, b, r)
+	e.BroadCast(1)
+	e.BroadCast(2)
+	assert.Equal(t, 1, <-b.entered)
+
+	// queued events of unregistered listener are dropped, so wait does not count them
+	e.Unregister <- b
+	close(b.release)
+	e.Wait()
+	assert.Equal(t, []interface{}{1, 2}, r.received())
+}
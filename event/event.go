@@ -35,4 +35,10 @@ type OrderEvent struct {
 	Event
 	State string
 	Oid   string
+	// Size, Price executed size and price of this event
+	// zero means store does not know, then remain size of order and order price are used
+	Size  int64
+	Price float64
+	// Commission paid for this event, zero means store does not know, then commission rate of broker is used
+	Commission float64
 }
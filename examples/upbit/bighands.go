@@ -7,30 +7,38 @@ import (
 	"github.com/gobenpark/trader/container"
 	"github.com/gobenpark/trader/indicators"
 	"github.com/gobenpark/trader/order"
+	"github.com/gobenpark/trader/trade"
 )
 
 type Bighands struct {
 	Broker broker.Broker
-	indi   indicators.Indicator
+	// indi is rsi, obv and sma of every code
+	indi map[string][]indicators.Indicator
 }
 
 func (s *Bighands) Next(broker *broker.Broker, container container.Container) {
-	rsi := indicators.NewRsi(14)
-	rsi.Calculate(container)
-	fmt.Println(rsi.Get()[0])
-
-	fmt.Println(broker.GetCash())
-
-	obv := indicators.NewObv()
+	if s.indi == nil {
+		s.indi = make(map[string][]indicators.Indicator)
+	}
 
-	obv.Calculate(container)
-	fmt.Println(obv.Get()[0])
-	fmt.Println(container.Code())
+	indi, ok := s.indi[container.Code()]
+	if !ok {
+		// indicators are made once and updated by container with every new candle
+		indi = []indicators.Indicator{indicators.NewRsi(14), indicators.NewObv(), indicators.NewSma(20)}
+		for _, i := range indi {
+			i.Calculate(container)
+			container.Register(i)
+		}
+		s.indi[container.Code()] = indi
+	}
 
-	sma := indicators.NewSma(20)
-	sma.Calculate(container)
-	fmt.Println(sma.Get()[0])
+	fmt.Println(broker.GetCash())
 	fmt.Println(container.Code())
+	for _, i := range indi {
+		if v := i.Get(); len(v) != 0 {
+			fmt.Println(v[0])
+		}
+	}
 }
 
 func (s *Bighands) NotifyOrder(o *order.Order) {
@@ -59,14 +67,16 @@ func (s *Bighands) NotifyOrder(o *order.Order) {
 	}
 }
 
-func (s *Bighands) NotifyTrade() {
-	panic("implement me")
+func (s *Bighands) NotifyTrade(t *trade.Trade) {
+	if !t.IsOpen() {
+		fmt.Printf("%s:closed pnl %f\n", t.Code, t.PnLComm())
+	}
 }
 
-func (s *Bighands) NotifyCashValue() {
-	panic("implement me")
+func (s *Bighands) NotifyCashValue(cash, value float64) {
+	fmt.Printf("cash %f value %f\n", cash, value)
 }
 
-func (s *Bighands) NotifyFund() {
-	panic("implement me")
+func (s *Bighands) NotifyFund(fund *broker.Fund) {
+	fmt.Printf("fund value %f\n", fund.FundValue)
 }
@@ -99,11 +99,16 @@ func (s *store) LoadTick(ctx context.Context, code string) (<-chan container.Tic
 }
 
 func (s *store) Order(o *order.Order) error {
+	otype := stock.OrderType_LimitOrder
+	if o.ExecType == order.Market {
+		otype = stock.OrderType_MarketOrder
+	}
+
 	switch o.OType {
 	case order.Buy:
 		_, err := s.cli.Buy(context.Background(), &stock.BuyRequest{
 			Code:       o.Code,
-			Otype:      stock.OrderType_LimitOrder,
+			Otype:      otype,
 			Volume:     float64(o.Size),
 			Price:      o.Price,
 			Identifier: o.UUID,
@@ -114,7 +119,7 @@ func (s *store) Order(o *order.Order) error {
 	case order.Sell:
 		_, err := s.cli.Sell(context.Background(), &stock.SellRequest{
 			Code:       o.Code,
-			Otype:      stock.OrderType_LimitOrder,
+			Otype:      otype,
 			Volume:     float64(o.Size),
 			Price:      o.Price,
 			Identifier: o.UUID,
@@ -0,0 +1,80 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package indicators
+
+import (
+	"time"
+
+	"github.com/gobenpark/trader/container"
+)
+
+// atrCalc is average true range with wilder smoothing seeded by simple average of first period true ranges
+type atrCalc struct {
+	period int
+	tr     trCalc
+	count  int
+	sum    float64
+	value  float64
+}
+
+func newAtrCalc(period int) *atrCalc {
+	if period < 1 {
+		period = 1
+	}
+	return &atrCalc{period: period}
+}
+
+// next apply candle and return average if period is satisfied
+func (a *atrCalc) next(candle container.Candle) (float64, bool) {
+	tr := a.tr.next(candle)
+	a.count++
+	switch {
+	case a.count < a.period:
+		a.sum += tr
+		return 0, false
+	case a.count == a.period:
+		a.value = (a.sum + tr) / float64(a.period)
+	default:
+		a.value = (a.value*float64(a.period-1) + tr) / float64(a.period)
+	}
+	return a.value, true
+}
+
+type atr struct {
+	calc      *atrCalc
+	last      time.Time
+	indicates *History
+}
+
+func NewAtr(period int, opts ...Option) Indicator {
+	c := newConfig(opts...)
+	return &atr{calc: newAtrCalc(period), indicates: NewHistory(c.history)}
+}
+
+func (a *atr) Update(candle container.Candle) {
+	a.last = candle.Date
+	if v, ok := a.calc.next(candle); ok {
+		a.indicates.Push(Indicate{Data: v, Date: candle.Date})
+	}
+}
+
+func (a *atr) Calculate(container container.Container) {
+	calculate(container, a.last, a.Update)
+}
+
+func (a *atr) Get() []Indicate {
+	return a.indicates.Values()
+}
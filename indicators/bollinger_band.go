@@ -17,68 +17,59 @@ package indicators
 
 import (
 	"math"
+	"time"
 
 	"github.com/gobenpark/trader/container"
 )
 
 type BollingerBand struct {
 	period int
-	Top    []Indicate
-	Mid    []Indicate
-	Bottom []Indicate
+	closes *window
+	last   time.Time
+	Top    *History
+	Mid    *History
+	Bottom *History
 }
 
-func NewBollingerBand(period int) *BollingerBand {
-	return &BollingerBand{period: period}
-}
-
-func (b *BollingerBand) mean(data []container.Candle) float64 {
-	total := 0.0
-	for _, i := range data {
-		total += i.Close
+func NewBollingerBand(period int, opts ...Option) *BollingerBand {
+	c := newConfig(opts...)
+	return &BollingerBand{
+		period: period,
+		closes: newWindow(period),
+		Top:    NewHistory(c.history),
+		Mid:    NewHistory(c.history),
+		Bottom: NewHistory(c.history),
 	}
-
-	return total / float64(len(data))
 }
 
-func (b *BollingerBand) standardDeviation(mean float64, data []container.Candle) float64 {
-	total := 0.0
-	for _, i := range data {
-		da := i.Close - mean
-		total += math.Pow(da, 2)
-	}
-	return math.Sqrt(total / float64(len(data)))
-}
-
-func (b *BollingerBand) Calculate(c container.Container) {
-	con := c.Values()
-	if len(con) < b.period {
+func (b *BollingerBand) Update(candle container.Candle) {
+	b.last = candle.Date
+	if b.closes.push(candle.Close); !b.closes.full() {
 		return
 	}
 
-	slice := len(con) - b.period
-	for i := slice - 1; i >= 0; i-- {
-		mean := b.mean(con[i : i+b.period])
-		sd := b.standardDeviation(mean, con[i:i+b.period])
-
-		b.Mid = append([]Indicate{{
-			Data: mean,
-			Date: con[i].Date,
-		}}, b.Mid...)
-
-		b.Top = append([]Indicate{{
-			Data: mean + (sd * 2),
-			Date: con[i].Date,
-		}}, b.Top...)
-
-		b.Bottom = append([]Indicate{{
-			Data: mean - (sd * 2),
-			Date: con[i].Date,
-		}}, b.Bottom...)
+	// deviation is recalculated from window, running sum of squares lose precision on large price
+	mean := b.closes.mean()
+	variance := 0.0
+	for ago := 0; ago < b.period; ago++ {
+		d := b.closes.at(ago) - mean
+		variance += d * d
 	}
+	sd := math.Sqrt(variance / float64(b.period))
+	b.Mid.Push(Indicate{Data: mean, Date: candle.Date})
+	b.Top.Push(Indicate{Data: mean + (sd * 2), Date: candle.Date})
+	b.Bottom.Push(Indicate{Data: mean - (sd * 2), Date: candle.Date})
+}
 
+func (b *BollingerBand) Calculate(c container.Container) {
+	calculate(c, b.last, b.Update)
 }
 
+// Get is mid line
 func (b *BollingerBand) Get() []Indicate {
-	panic("implement me")
+	return b.Mid.Values()
+}
+
+func (b *BollingerBand) Bands() (top, mid, bottom *History) {
+	return b.Top, b.Mid, b.Bottom
 }
@@ -48,9 +48,52 @@ func TestBollingerBand_Calculate(t *testing.T) {
 		})
 	}
 	b.Calculate(c)
+
+	// compare with bands calculated from every window
+	values := c.Values()
+	assert.Equal(t, len(values)-19, b.Mid.Len())
+	for ago := 0; ago < 5; ago++ {
+		window := values[ago : ago+20]
+		mean := 0.0
+		for _, v := range window {
+			mean += v.Close
+		}
+		mean /= 20
+		sd := 0.0
+		for _, v := range window {
+			sd += math.Pow(v.Close-mean, 2)
+		}
+		sd = math.Sqrt(sd / 20)
+
+		mid, _ := b.Mid.At(ago)
+		top, _ := b.Top.At(ago)
+		bottom, _ := b.Bottom.At(ago)
+		assert.InDelta(t, mean, mid.Data, 1e-6)
+		assert.InDelta(t, mean+sd*2, top.Data, 1e-6)
+		assert.InDelta(t, mean-sd*2, bottom.Data, 1e-6)
+		assert.Equal(t, window[0].Date, mid.Date)
+	}
 }
 
 func TestRoot(t *testing.T) {
 	fmt.Println(math.Sqrt(4))
 	fmt.Println(math.Pow(-10, 2))
 }
+
+func TestBollingerBand_LargePrice(t *testing.T) {
+	b := NewBollingerBand(20)
+	start := time.Date(2021, 3, 20, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 1000; i++ {
+		// close is 1e8 and 1e8+1 in turn, so deviation is always 0.5
+		b.Update(container.Candle{Close: 1e8 + float64(i%2), Date: start.Add(time.Duration(i) * time.Minute)})
+	}
+
+	for ago := 0; ago < b.Mid.Len(); ago++ {
+		mid, _ := b.Mid.At(ago)
+		top, _ := b.Top.At(ago)
+		bottom, _ := b.Bottom.At(ago)
+		assert.InDelta(t, 1e8+0.5, mid.Data, 1e-6)
+		assert.InDelta(t, 1e8+1.5, top.Data, 1e-6)
+		assert.InDelta(t, 1e8-0.5, bottom.Data, 1e-6)
+	}
+}
@@ -0,0 +1,65 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package indicators
+
+import (
+	"math"
+	"time"
+
+	"github.com/gobenpark/trader/container"
+)
+
+// cci is commodity channel index of typical price (high+low+close)/3
+// mean deviation needs every price of period, so update is O(period)
+type cci struct {
+	prices    *window
+	last      time.Time
+	indicates *History
+}
+
+func NewCci(period int, opts ...Option) Indicator {
+	c := newConfig(opts...)
+	return &cci{prices: newWindow(period), indicates: NewHistory(c.history)}
+}
+
+func (c *cci) Update(candle container.Candle) {
+	c.last = candle.Date
+	tp := (candle.High + candle.Low + candle.Close) / 3
+	if c.prices.push(tp); !c.prices.full() {
+		return
+	}
+
+	mean := c.prices.mean()
+	deviation := 0.0
+	for _, p := range c.prices.buf {
+		deviation += math.Abs(p - mean)
+	}
+	deviation /= float64(len(c.prices.buf))
+
+	v := 0.0
+	if deviation != 0 {
+		v = (tp - mean) / (0.015 * deviation)
+	}
+	c.indicates.Push(Indicate{Data: v, Date: candle.Date})
+}
+
+func (c *cci) Calculate(container container.Container) {
+	calculate(container, c.last, c.Update)
+}
+
+func (c *cci) Get() []Indicate {
+	return c.indicates.Values()
+}
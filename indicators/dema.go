@@ -0,0 +1,56 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package indicators
+
+import (
+	"time"
+
+	"github.com/gobenpark/trader/container"
+)
+
+// dema is double exponential moving average, 2*ema - ema(ema)
+type dema struct {
+	ema1      *emaCalc
+	ema2      *emaCalc
+	last      time.Time
+	indicates *History
+}
+
+func NewDema(period int, opts ...Option) Indicator {
+	c := newConfig(opts...)
+	return &dema{ema1: newEmaCalc(period), ema2: newEmaCalc(period), indicates: NewHistory(c.history)}
+}
+
+func (d *dema) Update(candle container.Candle) {
+	d.last = candle.Date
+	v1, ok := d.ema1.next(candle.Close)
+	if !ok {
+		return
+	}
+	v2, ok := d.ema2.next(v1)
+	if !ok {
+		return
+	}
+	d.indicates.Push(Indicate{Data: 2*v1 - v2, Date: candle.Date})
+}
+
+func (d *dema) Calculate(container container.Container) {
+	calculate(container, d.last, d.Update)
+}
+
+func (d *dema) Get() []Indicate {
+	return d.indicates.Values()
+}
@@ -0,0 +1,101 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package indicators
+
+import (
+	"time"
+
+	"github.com/gobenpark/trader/container"
+)
+
+// extreme is highest or lowest value of last period values by monotonic queue
+type extreme struct {
+	period int
+	count  int
+	// better is true if a should stay in queue instead of b
+	better func(a, b float64) bool
+	index  []int
+	values []float64
+}
+
+func newExtreme(period int, better func(a, b float64) bool) *extreme {
+	if period < 1 {
+		period = 1
+	}
+	return &extreme{period: period, better: better}
+}
+
+// next apply value and return extreme if period is satisfied
+func (e *extreme) next(v float64) (float64, bool) {
+	for len(e.values) != 0 && !e.better(e.values[len(e.values)-1], v) {
+		e.values = e.values[:len(e.values)-1]
+		e.index = e.index[:len(e.index)-1]
+	}
+	e.values = append(e.values, v)
+	e.index = append(e.index, e.count)
+	if e.index[0] <= e.count-e.period {
+		e.values = e.values[1:]
+		e.index = e.index[1:]
+	}
+	e.count++
+	return e.values[0], e.count >= e.period
+}
+
+// Donchian is donchian channel, Top is highest high and Bottom is lowest low of period and Mid is middle of them
+type Donchian struct {
+	high   *extreme
+	low    *extreme
+	last   time.Time
+	Top    *History
+	Mid    *History
+	Bottom *History
+}
+
+func NewDonchian(period int, opts ...Option) *Donchian {
+	c := newConfig(opts...)
+	return &Donchian{
+		high:   newExtreme(period, func(a, b float64) bool { return a > b }),
+		low:    newExtreme(period, func(a, b float64) bool { return a < b }),
+		Top:    NewHistory(c.history),
+		Mid:    NewHistory(c.history),
+		Bottom: NewHistory(c.history),
+	}
+}
+
+func (d *Donchian) Update(candle container.Candle) {
+	d.last = candle.Date
+	top, ok := d.high.next(candle.High)
+	bottom, _ := d.low.next(candle.Low)
+	if !ok {
+		return
+	}
+	d.Top.Push(Indicate{Data: top, Date: candle.Date})
+	d.Mid.Push(Indicate{Data: (top + bottom) / 2, Date: candle.Date})
+	d.Bottom.Push(Indicate{Data: bottom, Date: candle.Date})
+}
+
+func (d *Donchian) Calculate(c container.Container) {
+	calculate(c, d.last, d.Update)
+}
+
+// Get is mid line
+func (d *Donchian) Get() []Indicate {
+	return d.Mid.Values()
+}
+
+func (d *Donchian) Bands() (top, mid, bottom *History) {
+	return d.Top, d.Mid, d.Bottom
+}
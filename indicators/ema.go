@@ -0,0 +1,80 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package indicators
+
+import (
+	"time"
+
+	"github.com/gobenpark/trader/container"
+)
+
+// emaCalc is exponential moving average of values seeded by simple average of first period values
+type emaCalc struct {
+	period int
+	alpha  float64
+	count  int
+	sum    float64
+	value  float64
+}
+
+func newEmaCalc(period int) *emaCalc {
+	if period < 1 {
+		period = 1
+	}
+	return &emaCalc{period: period, alpha: 2.0 / float64(period+1)}
+}
+
+// next apply value and return average if period is satisfied
+func (e *emaCalc) next(v float64) (float64, bool) {
+	e.count++
+	switch {
+	case e.count < e.period:
+		e.sum += v
+		return 0, false
+	case e.count == e.period:
+		e.value = (e.sum + v) / float64(e.period)
+	default:
+		e.value += e.alpha * (v - e.value)
+	}
+	return e.value, true
+}
+
+// ema is exponential moving average of close with alpha 2/(period+1)
+type ema struct {
+	calc      *emaCalc
+	last      time.Time
+	indicates *History
+}
+
+func NewEma(period int, opts ...Option) Indicator {
+	c := newConfig(opts...)
+	return &ema{calc: newEmaCalc(period), indicates: NewHistory(c.history)}
+}
+
+func (e *ema) Update(candle container.Candle) {
+	e.last = candle.Date
+	if v, ok := e.calc.next(candle.Close); ok {
+		e.indicates.Push(Indicate{Data: v, Date: candle.Date})
+	}
+}
+
+func (e *ema) Calculate(container container.Container) {
+	calculate(container, e.last, e.Update)
+}
+
+func (e *ema) Get() []Indicate {
+	return e.indicates.Values()
+}
@@ -0,0 +1,121 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package indicators
+
+import "sync"
+
+// History is ring buffer of latest indicates
+type History struct {
+	mu    sync.RWMutex
+	buf   []Indicate
+	start int
+	size  int
+}
+
+func NewHistory(capacity int) *History {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &History{buf: make([]Indicate, capacity)}
+}
+
+// Push add indicate, oldest one is dropped when history is full
+func (h *History) Push(i Indicate) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.size < len(h.buf) {
+		h.buf[(h.start+h.size)%len(h.buf)] = i
+		h.size++
+		return
+	}
+	h.buf[h.start] = i
+	h.start = (h.start + 1) % len(h.buf)
+}
+
+func (h *History) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.size
+}
+
+// At is indicate of ago, 0 is latest
+func (h *History) At(ago int) (Indicate, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if ago < 0 || ago >= h.size {
+		return Indicate{}, false
+	}
+	return h.buf[(h.start+h.size-1-ago)%len(h.buf)], true
+}
+
+// Last is latest indicate
+func (h *History) Last() (Indicate, bool) {
+	return h.At(0)
+}
+
+// Values is indicates in order of latest first
+func (h *History) Values() []Indicate {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	values := make([]Indicate, h.size)
+	for i := range values {
+		values[i] = h.buf[(h.start+h.size-1-i)%len(h.buf)]
+	}
+	return values
+}
+
+// window is ring buffer of latest values with sum of them
+type window struct {
+	buf   []float64
+	start int
+	size  int
+	sum   float64
+}
+
+func newWindow(period int) *window {
+	if period < 1 {
+		period = 1
+	}
+	return &window{buf: make([]float64, period)}
+}
+
+// push add value and return dropped oldest value when window is full
+func (w *window) push(v float64) (float64, bool) {
+	w.sum += v
+	if w.size < len(w.buf) {
+		w.buf[(w.start+w.size)%len(w.buf)] = v
+		w.size++
+		return 0, false
+	}
+	old := w.buf[w.start]
+	w.buf[w.start] = v
+	w.start = (w.start + 1) % len(w.buf)
+	w.sum -= old
+	return old, true
+}
+
+func (w *window) full() bool {
+	return w.size == len(w.buf)
+}
+
+func (w *window) mean() float64 {
+	return w.sum / float64(w.size)
+}
+
+// at is value of ago, 0 is latest
+func (w *window) at(ago int) float64 {
+	return w.buf[(w.start+w.size-1-ago)%len(w.buf)]
+}
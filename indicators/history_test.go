@@ -0,0 +1,71 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package indicators
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gobenpark/trader/container"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistory(t *testing.T) {
+	h := NewHistory(3)
+	_, ok := h.Last()
+	assert.False(t, ok)
+
+	for i := 1; i <= 5; i++ {
+		h.Push(Indicate{Data: float64(i)})
+	}
+	assert.Equal(t, 3, h.Len())
+	assert.Equal(t, []Indicate{{Data: 5}, {Data: 4}, {Data: 3}}, h.Values())
+
+	last, ok := h.Last()
+	assert.True(t, ok)
+	assert.Equal(t, 5.0, last.Data)
+	i, ok := h.At(2)
+	assert.True(t, ok)
+	assert.Equal(t, 3.0, i.Data)
+	_, ok = h.At(3)
+	assert.False(t, ok)
+}
+
+func TestIndicator_Register(t *testing.T) {
+	c := container.NewDataContainer(container.Info{Code: "code"})
+	sma := NewSma(3, WithHistory(2))
+	obv := NewObv()
+	c.Register(sma, obv)
+
+	start := time.Date(2021, 3, 12, 0, 0, 0, 0, time.UTC)
+	for i, v := range []float64{1, 2, 3, 2, 5} {
+		c.Add(container.Candle{Code: "code", Close: v, Volume: 10, Date: start.Add(time.Duration(i) * time.Minute)})
+	}
+
+	assert.Equal(t, []Indicate{
+		{Data: 10.0 / 3, Date: start.Add(4 * time.Minute)},
+		{Data: 7.0 / 3, Date: start.Add(3 * time.Minute)},
+	}, sma.Get())
+
+	// rise, rise, fall, rise
+	assert.Len(t, obv.Get(), 4)
+	assert.Equal(t, 20.0, obv.Get()[0].Data)
+
+	// calculate after register does not apply same candle again
+	sma.Calculate(c)
+	assert.Len(t, sma.Get(), 2)
+	assert.Equal(t, start.Add(4*time.Minute), sma.Get()[0].Date)
+}
@@ -0,0 +1,62 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package indicators
+
+import (
+	"math"
+	"time"
+
+	"github.com/gobenpark/trader/container"
+)
+
+// hma is hull moving average, wma(2*wma(period/2) - wma(period), sqrt(period))
+type hma struct {
+	half      *wmaCalc
+	full      *wmaCalc
+	smooth    *wmaCalc
+	last      time.Time
+	indicates *History
+}
+
+func NewHma(period int, opts ...Option) Indicator {
+	c := newConfig(opts...)
+	return &hma{
+		half:      newWmaCalc(period / 2),
+		full:      newWmaCalc(period),
+		smooth:    newWmaCalc(int(math.Sqrt(float64(period)))),
+		indicates: NewHistory(c.history),
+	}
+}
+
+func (h *hma) Update(candle container.Candle) {
+	h.last = candle.Date
+	half, _ := h.half.next(candle.Close)
+	full, ok := h.full.next(candle.Close)
+	if !ok {
+		return
+	}
+	if v, ok := h.smooth.next(2*half - full); ok {
+		h.indicates.Push(Indicate{Data: v, Date: candle.Date})
+	}
+}
+
+func (h *hma) Calculate(container container.Container) {
+	calculate(container, h.last, h.Update)
+}
+
+func (h *hma) Get() []Indicate {
+	return h.indicates.Values()
+}
@@ -21,12 +21,60 @@ import (
 	"github.com/gobenpark/trader/container"
 )
 
+// DefaultHistory is number of indicates kept by indicator
+const DefaultHistory = 500
+
+// Indicator is updated with every candle in O(1), and keeps bounded history of indicates
+// it can be registered with container so it is updated whenever candle is added
 type Indicator interface {
+	container.Listener
+	// Calculate update indicator with candles of container which are newer than last updated candle
 	Calculate(container container.Container)
+	// Get is indicates in order of latest first
 	Get() []Indicate
 }
 
+// Band is indicator of top, mid and bottom lines which are aligned by date, Get is mid line
+type Band interface {
+	Indicator
+	Bands() (top, mid, bottom *History)
+}
+
 type Indicate struct {
 	Data float64
 	Date time.Time
 }
+
+type Option func(*config)
+
+type config struct {
+	history int
+}
+
+// WithHistory set number of indicates kept by indicator
+func WithHistory(size int) Option {
+	return func(c *config) {
+		c.history = size
+	}
+}
+
+func newConfig(opts ...Option) config {
+	c := config{history: DefaultHistory}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if c.history < 1 {
+		c.history = 1
+	}
+	return c
+}
+
+// calculate apply candles of container which are newer than last to update in chronological order
+func calculate(c container.Container, last time.Time, update func(candle container.Candle)) {
+	values := c.Values()
+	for i := len(values) - 1; i >= 0; i-- {
+		if last.IsZero() || values[i].Date.After(last) {
+			update(values[i])
+		}
+	}
+}
@@ -0,0 +1,82 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package indicators
+
+import (
+	"math"
+	"time"
+
+	"github.com/gobenpark/trader/container"
+)
+
+// kama is kaufman adaptive moving average
+// smoothing moves between ema of fast and slow period by efficiency ratio of period
+// first average is started from previous close when period+1 candles are given
+type kama struct {
+	period    int
+	fast      float64
+	slow      float64
+	closes    *window
+	changes   *window
+	value     float64
+	init      bool
+	last      time.Time
+	indicates *History
+}
+
+func NewKama(period, fast, slow int, opts ...Option) Indicator {
+	c := newConfig(opts...)
+	return &kama{
+		period:    period,
+		fast:      2.0 / float64(fast+1),
+		slow:      2.0 / float64(slow+1),
+		closes:    newWindow(period + 1),
+		changes:   newWindow(period),
+		indicates: NewHistory(c.history),
+	}
+}
+
+func (k *kama) Update(candle container.Candle) {
+	k.last = candle.Date
+	if k.closes.size != 0 {
+		k.changes.push(math.Abs(candle.Close - k.closes.at(0)))
+	}
+	k.closes.push(candle.Close)
+	if !k.closes.full() {
+		return
+	}
+
+	if !k.init {
+		k.value = k.closes.at(1)
+		k.init = true
+	}
+
+	er := 0.0
+	if volatility := k.changes.sum; volatility != 0 {
+		er = math.Abs(candle.Close-k.closes.at(k.period)) / volatility
+	}
+	sc := math.Pow(er*(k.fast-k.slow)+k.slow, 2)
+	k.value += sc * (candle.Close - k.value)
+	k.indicates.Push(Indicate{Data: k.value, Date: candle.Date})
+}
+
+func (k *kama) Calculate(container container.Container) {
+	calculate(container, k.last, k.Update)
+}
+
+func (k *kama) Get() []Indicate {
+	return k.indicates.Values()
+}
@@ -0,0 +1,70 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package indicators
+
+import (
+	"time"
+
+	"github.com/gobenpark/trader/container"
+)
+
+// Keltner is keltner channel, Mid is ema of close and Top, Bottom are multiplier times atr away from it
+type Keltner struct {
+	ema        *emaCalc
+	atr        *atrCalc
+	multiplier float64
+	last       time.Time
+	Top        *History
+	Mid        *History
+	Bottom     *History
+}
+
+func NewKeltner(period, atrPeriod int, multiplier float64, opts ...Option) *Keltner {
+	c := newConfig(opts...)
+	return &Keltner{
+		ema:        newEmaCalc(period),
+		atr:        newAtrCalc(atrPeriod),
+		multiplier: multiplier,
+		Top:        NewHistory(c.history),
+		Mid:        NewHistory(c.history),
+		Bottom:     NewHistory(c.history),
+	}
+}
+
+func (k *Keltner) Update(candle container.Candle) {
+	k.last = candle.Date
+	mid, ok := k.ema.next(candle.Close)
+	atr, aok := k.atr.next(candle)
+	if !ok || !aok {
+		return
+	}
+	k.Mid.Push(Indicate{Data: mid, Date: candle.Date})
+	k.Top.Push(Indicate{Data: mid + atr*k.multiplier, Date: candle.Date})
+	k.Bottom.Push(Indicate{Data: mid - atr*k.multiplier, Date: candle.Date})
+}
+
+func (k *Keltner) Calculate(c container.Container) {
+	calculate(c, k.last, k.Update)
+}
+
+// Get is mid line
+func (k *Keltner) Get() []Indicate {
+	return k.Mid.Values()
+}
+
+func (k *Keltner) Bands() (top, mid, bottom *History) {
+	return k.Top, k.Mid, k.Bottom
+}
@@ -0,0 +1,84 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package indicators
+
+import (
+	"time"
+
+	"github.com/gobenpark/trader/container"
+)
+
+// MACD is difference of fast and slow ema of close with signal ema of it
+// MACD, Signal and Histogram are aligned, they are pushed together after signal period is satisfied
+type MACD struct {
+	fast      *emaCalc
+	slow      *emaCalc
+	signal    *emaCalc
+	last      time.Time
+	MACD      *History
+	Signal    *History
+	Histogram *History
+}
+
+// NewMacd swap fast and slow period if they are reversed, and slow period is extended if they are same
+// because line of same period is always zero
+func NewMacd(fast, slow, signal int, opts ...Option) *MACD {
+	if fast > slow {
+		fast, slow = slow, fast
+	}
+	if fast < 1 {
+		fast = 1
+	}
+	if slow <= fast {
+		slow = fast + 1
+	}
+	c := newConfig(opts...)
+	return &MACD{
+		fast:      newEmaCalc(fast),
+		slow:      newEmaCalc(slow),
+		signal:    newEmaCalc(signal),
+		MACD:      NewHistory(c.history),
+		Signal:    NewHistory(c.history),
+		Histogram: NewHistory(c.history),
+	}
+}
+
+func (m *MACD) Update(candle container.Candle) {
+	m.last = candle.Date
+	fast, _ := m.fast.next(candle.Close)
+	slow, ok := m.slow.next(candle.Close)
+	if !ok {
+		return
+	}
+
+	line := fast - slow
+	signal, ok := m.signal.next(line)
+	if !ok {
+		return
+	}
+	m.MACD.Push(Indicate{Data: line, Date: candle.Date})
+	m.Signal.Push(Indicate{Data: signal, Date: candle.Date})
+	m.Histogram.Push(Indicate{Data: line - signal, Date: candle.Date})
+}
+
+func (m *MACD) Calculate(c container.Container) {
+	calculate(c, m.last, m.Update)
+}
+
+// Get is macd line
+func (m *MACD) Get() []Indicate {
+	return m.MACD.Values()
+}
@@ -0,0 +1,54 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package indicators
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMACD(t *testing.T) {
+	m := NewMacd(12, 26, 9)
+	m.Calculate(sampleContainer(t))
+
+	assertResult(t, readColumn(t, "macd_result.csv", 1), m.MACD.Values())
+	assertResult(t, readColumn(t, "macd_result.csv", 2), m.Signal.Values())
+	assertResult(t, readColumn(t, "macd_result.csv", 3), m.Histogram.Values())
+	assert.Equal(t, m.MACD.Values(), m.Get())
+}
+
+func TestNewMacd_Period(t *testing.T) {
+	m := NewMacd(12, 26, 9)
+	assert.Equal(t, 12, m.fast.period)
+	assert.Equal(t, 26, m.slow.period)
+
+	m = NewMacd(26, 12, 9)
+	assert.Equal(t, 12, m.fast.period)
+	assert.Equal(t, 26, m.slow.period)
+
+	m = NewMacd(12, 12, 9)
+	assert.Equal(t, 12, m.fast.period)
+	assert.Equal(t, 13, m.slow.period)
+
+	m = NewMacd(0, -3, 9)
+	assert.Equal(t, 1, m.fast.period)
+	assert.Equal(t, 2, m.slow.period)
+
+	reversed := NewMacd(26, 12, 9)
+	reversed.Calculate(sampleContainer(t))
+	assertResult(t, readColumn(t, "macd_result.csv", 1), reversed.MACD.Values())
+}
@@ -0,0 +1,102 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package indicators
+
+import (
+	"time"
+
+	"github.com/gobenpark/trader/container"
+)
+
+// mfi is money flow index, money flow is typical price times volume
+// and it is positive when typical price rises from previous candle, first value needs period+1 candles
+type mfi struct {
+	prev     float64
+	init     bool
+	positive *window
+	negative *window
+	// rises, falls is count of non zero positive, negative flows in period
+	// sum of flows can be not exactly zero after they are dropped, so zero flow is checked with them
+	rises     *window
+	falls     *window
+	last      time.Time
+	indicates *History
+}
+
+func NewMfi(period int, opts ...Option) Indicator {
+	c := newConfig(opts...)
+	return &mfi{
+		positive:  newWindow(period),
+		negative:  newWindow(period),
+		rises:     newWindow(period),
+		falls:     newWindow(period),
+		indicates: NewHistory(c.history),
+	}
+}
+
+func (m *mfi) Update(candle container.Candle) {
+	m.last = candle.Date
+	tp := (candle.High + candle.Low + candle.Close) / 3
+	if !m.init {
+		m.prev = tp
+		m.init = true
+		return
+	}
+
+	flow := tp * candle.Volume
+	var positive, negative float64
+	switch {
+	case tp > m.prev:
+		positive = flow
+	case tp < m.prev:
+		negative = flow
+	}
+	m.positive.push(positive)
+	m.negative.push(negative)
+	m.rises.push(nonZero(positive))
+	m.falls.push(nonZero(negative))
+	m.prev = tp
+	if !m.positive.full() {
+		return
+	}
+
+	// without negative flow it is 100, and without any flow like zero volume it is neutral 50
+	var v float64
+	switch {
+	case m.falls.sum == 0 && m.rises.sum == 0:
+		v = 50
+	case m.falls.sum == 0:
+		v = 100
+	default:
+		v = 100 - 100/(1+m.positive.sum/m.negative.sum)
+	}
+	m.indicates.Push(Indicate{Data: v, Date: candle.Date})
+}
+
+func nonZero(v float64) float64 {
+	if v != 0 {
+		return 1
+	}
+	return 0
+}
+
+func (m *mfi) Calculate(container container.Container) {
+	calculate(container, m.last, m.Update)
+}
+
+func (m *mfi) Get() []Indicate {
+	return m.indicates.Values()
+}
@@ -0,0 +1,116 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package indicators
+
+import (
+	"encoding/csv"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gobenpark/trader/container"
+	"github.com/stretchr/testify/assert"
+)
+
+// sampleContainer is container of candles in ticksample.csv
+func sampleContainer(t *testing.T) container.Container {
+	f, err := os.Open("ticksample.csv")
+	assert.NoError(t, err)
+	defer f.Close()
+
+	data, err := csv.NewReader(f).ReadAll()
+	assert.NoError(t, err)
+
+	stof := func(s string) float64 {
+		f, _ := strconv.ParseFloat(s, 64)
+		return f
+	}
+
+	c := container.NewDataContainer(container.Info{Code: "code"})
+	for _, i := range data[1:] {
+		ti, err := time.Parse("2006-01-02T15:04:05Z", i[6])
+		assert.NoError(t, err)
+		c.Add(container.Candle{
+			Code:   i[0],
+			Open:   stof(i[1]),
+			High:   stof(i[2]),
+			Low:    stof(i[3]),
+			Close:  stof(i[4]),
+			Volume: stof(i[5]),
+			Date:   ti,
+		})
+	}
+	return c
+}
+
+// readResult read reference indicates of date and value in order of latest first
+func readResult(t *testing.T, path string) []Indicate {
+	return readColumn(t, path, 1)
+}
+
+// readColumn read reference indicates of date and value of column in order of latest first
+func readColumn(t *testing.T, path string, column int) []Indicate {
+	f, err := os.Open(path)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	data, err := csv.NewReader(f).ReadAll()
+	assert.NoError(t, err)
+
+	result := make([]Indicate, len(data))
+	for i, row := range data {
+		date, err := time.Parse("2006-01-02 15:04:05", row[0])
+		assert.NoError(t, err)
+		v, err := strconv.ParseFloat(row[column], 64)
+		assert.NoError(t, err)
+		result[len(data)-1-i] = Indicate{Data: v, Date: date}
+	}
+	return result
+}
+
+func assertResult(t *testing.T, expected, actual []Indicate) {
+	assert.Len(t, actual, len(expected))
+	for i := range expected {
+		if i >= len(actual) {
+			return
+		}
+		assert.True(t, expected[i].Date.Equal(actual[i].Date), "date of %d", i)
+		assert.InDelta(t, expected[i].Data, actual[i].Data, 1e-6, "value of %d", i)
+	}
+}
+
+func TestMovingAverage(t *testing.T) {
+	tests := []struct {
+		name      string
+		indicator Indicator
+		result    string
+	}{
+		{"ema", NewEma(10), "ema_result.csv"},
+		{"dema", NewDema(10), "dema_result.csv"},
+		{"tema", NewTema(10), "tema_result.csv"},
+		{"wma", NewWma(10), "wma_result.csv"},
+		{"hma", NewHma(16), "hma_result.csv"},
+		{"kama", NewKama(10, 2, 30), "kama_result.csv"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.indicator.Calculate(sampleContainer(t))
+			assertResult(t, readResult(t, test.result), test.indicator.Get())
+		})
+	}
+}
@@ -15,47 +15,45 @@
 package indicators
 
 import (
+	"time"
+
 	"github.com/gobenpark/trader/container"
 )
 
 type OBV struct {
-	obvs []Indicate
+	obv  float64
+	prev float64
+	init bool
+	last time.Time
+	obvs *History
 }
 
-func NewObv() Indicator {
-	return &OBV{obvs: []Indicate{}}
+func NewObv(opts ...Option) Indicator {
+	c := newConfig(opts...)
+	return &OBV{obvs: NewHistory(c.history)}
 }
 
-func (o *OBV) Calculate(container container.Container) {
-	obv := float64(0)
-	value := container.Values()
-	length := container.Size()
-
-	if len(o.obvs) == 0 {
-		for i := length - 2; i >= 0; i-- {
-			if value[i].Close > value[i+1].Close {
-				obv = obv + value[i].Volume
-			} else if value[i].Close < value[i+1].Close {
-				obv = obv - value[i].Volume
-			}
-			o.obvs = append([]Indicate{{
-				Data: obv,
-				Date: value[i].Date,
-			}}, o.obvs...)
-		}
-	} else {
-		if value[0].Close > value[1].Close {
-			obv = o.obvs[0].Data + value[0].Volume
-		} else if value[0].Close < value[1].Close {
-			obv = o.obvs[0].Data - value[0].Volume
-		}
-		o.obvs = append([]Indicate{{
-			Data: obv,
-			Date: value[0].Date,
-		}})
+func (o *OBV) Update(candle container.Candle) {
+	o.last = candle.Date
+	if !o.init {
+		o.prev = candle.Close
+		o.init = true
+		return
+	}
+
+	if candle.Close > o.prev {
+		o.obv += candle.Volume
+	} else if candle.Close < o.prev {
+		o.obv -= candle.Volume
 	}
+	o.prev = candle.Close
+	o.obvs.Push(Indicate{Data: o.obv, Date: candle.Date})
+}
+
+func (o *OBV) Calculate(container container.Container) {
+	calculate(container, o.last, o.Update)
 }
 
 func (o *OBV) Get() []Indicate {
-	return o.obvs
+	return o.obvs.Values()
 }
@@ -0,0 +1,116 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package indicators
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gobenpark/trader/container"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOscillator(t *testing.T) {
+	tests := []struct {
+		name      string
+		indicator Indicator
+		result    string
+	}{
+		{"williams r", NewWilliamsR(14), "williams_r_result.csv"},
+		{"cci", NewCci(20), "cci_result.csv"},
+		{"mfi", NewMfi(14), "mfi_result.csv"},
+		{"roc", NewRoc(12), "roc_result.csv"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.indicator.Calculate(sampleContainer(t))
+			assertResult(t, readResult(t, test.result), test.indicator.Get())
+		})
+	}
+}
+
+func TestStochastic(t *testing.T) {
+	tests := []struct {
+		name       string
+		stochastic *Stochastic
+		result     string
+	}{
+		{"fast", NewStochastic(14, 3), "stochastic_result.csv"},
+		{"slow", NewSlowStochastic(14, 3, 3), "slow_stochastic_result.csv"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.stochastic.Calculate(sampleContainer(t))
+			assertResult(t, readColumn(t, test.result, 1), test.stochastic.K.Values())
+			assertResult(t, readColumn(t, test.result, 2), test.stochastic.D.Values())
+			assert.Equal(t, test.stochastic.K.Values(), test.stochastic.Get())
+		})
+	}
+}
+
+func TestOscillator_WarmUp(t *testing.T) {
+	tests := []struct {
+		indicator Indicator
+		// bars is number of candles needed for first value
+		bars int
+	}{
+		{NewStochastic(5, 3), 7},
+		{NewSlowStochastic(5, 3, 3), 9},
+		{NewWilliamsR(5), 5},
+		{NewCci(5), 5},
+		{NewMfi(5), 6},
+		{NewRoc(5), 6},
+	}
+
+	start := time.Date(2021, 3, 12, 0, 0, 0, 0, time.UTC)
+	for _, test := range tests {
+		for i := 0; i < test.bars; i++ {
+			assert.Empty(t, test.indicator.Get())
+			v := float64(i%3 + 1)
+			test.indicator.Update(container.Candle{Open: v, High: v + 1, Low: v - 1, Close: v, Volume: 10, Date: start.Add(time.Duration(i) * time.Minute)})
+		}
+		assert.Len(t, test.indicator.Get(), 1)
+	}
+}
+
+func TestMfi_ZeroFlow(t *testing.T) {
+	tests := []struct {
+		name     string
+		volume   func(i int) float64
+		expected float64
+	}{
+		{"zero volume is neutral", func(i int) float64 { return 0 }, 50},
+		{"falling candle of zero volume", func(i int) float64 { return float64(i % 2 * 10) }, 100},
+	}
+
+	start := time.Date(2021, 3, 12, 0, 0, 0, 0, time.UTC)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := NewMfi(3)
+			for i := 0; i < 8; i++ {
+				// odd candles rise and even candles fall
+				v := float64(10 + i%2)
+				m.Update(container.Candle{Open: v, High: v, Low: v, Close: v, Volume: test.volume(i), Date: start.Add(time.Duration(i) * time.Minute)})
+			}
+			for _, i := range m.Get() {
+				assert.Equal(t, test.expected, i.Data)
+			}
+			assert.Len(t, m.Get(), 5)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package indicators
+
+import (
+	"time"
+
+	"github.com/gobenpark/trader/container"
+)
+
+// roc is rate of change of close from period ago in percent
+type roc struct {
+	period    int
+	closes    *window
+	last      time.Time
+	indicates *History
+}
+
+func NewRoc(period int, opts ...Option) Indicator {
+	c := newConfig(opts...)
+	return &roc{period: period, closes: newWindow(period + 1), indicates: NewHistory(c.history)}
+}
+
+func (r *roc) Update(candle container.Candle) {
+	r.last = candle.Date
+	if r.closes.push(candle.Close); !r.closes.full() {
+		return
+	}
+
+	if prev := r.closes.at(r.period); prev != 0 {
+		r.indicates.Push(Indicate{Data: 100 * (candle.Close - prev) / prev, Date: candle.Date})
+	}
+}
+
+func (r *roc) Calculate(container container.Container) {
+	calculate(container, r.last, r.Update)
+}
+
+func (r *roc) Get() []Indicate {
+	return r.indicates.Values()
+}
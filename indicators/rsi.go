@@ -17,77 +17,71 @@ package indicators
 
 import (
 	"math"
+	"time"
 
 	"github.com/gobenpark/trader/container"
 )
 
+// rsi is relative strength index smoothed by 1/period
 type rsi struct {
 	period    int
-	indicates []Indicate
-	AD        []Indicate
-	AU        []Indicate
+	prev      float64
+	count     int
+	up        float64
+	down      float64
+	last      time.Time
+	indicates *History
 }
 
-func NewRsi(period int) Indicator {
+func NewRsi(period int, opts ...Option) Indicator {
 	if period == 0 {
 		period = 14
 	}
-	return &rsi{period: period, indicates: []Indicate{}}
+	c := newConfig(opts...)
+	return &rsi{period: period, indicates: NewHistory(c.history)}
 }
 
 //self.line[0] = self.line[-1] * self.alpha1 + self.data[0] * self.alpha
-func (r *rsi) Calculate(container container.Container) {
-	c := container.Values()
-	if len(c) > 100 {
-		c = c[:100]
-	}
-
-	slide := len(c) - r.period
-	if len(c) < r.period {
+func (r *rsi) Update(candle container.Candle) {
+	r.last = candle.Date
+	r.count++
+	if r.count == 1 {
+		r.prev = candle.Close
 		return
 	}
-	alpha := 1.0 / float64(r.period)
-	alpha1 := 1.0 - alpha
-	aprev := 0.0
-	uprev := 0.0
 
-	if v := c[slide].Close - c[slide+1].Close; v > 0 {
-		aprev = v
+	v := candle.Close - r.prev
+	r.prev = candle.Close
+	alpha := 1.0 / float64(r.period)
+	if r.count <= r.period+1 {
+		// simple average of first period changes is seed of averages
+		r.up += math.Max(v, 0) * alpha
+		r.down += math.Max(-v, 0) * alpha
 	} else {
-		uprev = math.Abs(v)
+		alpha1 := 1.0 - alpha
+		r.up = r.up*alpha1 + math.Max(v, 0)*alpha
+		r.down = r.down*alpha1 + math.Max(-v, 0)*alpha
 	}
 
-	var a []float64
-	var b []float64
-
-	for i := slide - 1; i >= 0; i-- {
-		if v := c[i].Close - c[i+1].Close; v >= 0 {
-			aprev = aprev*alpha1 + v*alpha
-			a = append([]float64{aprev}, a...)
-
-			uprev *= alpha1
-			b = append([]float64{uprev}, b...)
-		} else {
-			aprev *= alpha1
-			a = append([]float64{aprev}, a...)
-
-			uprev = uprev*alpha1 + math.Abs(v)*alpha
-			b = append([]float64{uprev}, b...)
-		}
+	if r.count <= r.period {
+		return
+	}
 
-		rs := aprev / uprev
-		rsi := 100.0 - 100.0/(1.0+rs)
-		r.indicates = append([]Indicate{{
-			Data: rsi,
-			Date: c[i].Date,
-		}}, r.indicates...)
+	value := 100.0
+	if r.down != 0 {
+		value = 100.0 - 100.0/(1.0+r.up/r.down)
 	}
+	r.indicates.Push(Indicate{Data: value, Date: candle.Date})
+}
+
+func (r *rsi) Calculate(container container.Container) {
+	calculate(container, r.last, r.Update)
 }
 
 func (r *rsi) Get() []Indicate {
-	return r.indicates
+	return r.indicates.Values()
 }
 
 func (r *rsi) PeriodSatisfaction() bool {
-	panic("implement me")
+	return r.count > r.period
 }
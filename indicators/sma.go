@@ -16,49 +16,39 @@
 package indicators
 
 import (
+	"time"
+
 	"github.com/gobenpark/trader/container"
 )
 
 type sma struct {
-	period    int
-	indicates []Indicate
+	closes    *window
+	last      time.Time
+	indicates *History
 }
 
-func NewSma(period int) Indicator {
-	return &sma{period: period}
+func NewSma(period int, opts ...Option) Indicator {
+	c := newConfig(opts...)
+	return &sma{closes: newWindow(period), indicates: NewHistory(c.history)}
 }
 
-func (s *sma) Calculate(container container.Container) {
-	size := container.Size()
-	var indicates []Indicate
-	if size >= s.period {
-		slide := (size - s.period)
-		candle := container.Values()
-
-		for i := 0; i <= slide; i++ {
-			id := Indicate{
-				Data: average(candle[i : s.period+i]),
-				Date: candle[i].Date,
-			}
-
-			if len(s.indicates) != 0 {
-				if id.Date.After(s.indicates[0].Date) {
-					indicates = append(indicates, id)
-					continue
-				}
-				break
-			} else {
-				indicates = append(indicates, id)
-			}
-		}
-		s.indicates = append(indicates, s.indicates...)
+func (s *sma) Update(candle container.Candle) {
+	s.last = candle.Date
+	s.closes.push(candle.Close)
+	if s.closes.full() {
+		s.indicates.Push(Indicate{Data: s.closes.mean(), Date: candle.Date})
 	}
 }
 
+func (s *sma) Calculate(container container.Container) {
+	calculate(container, s.last, s.Update)
+}
+
 func (s *sma) Get() []Indicate {
-	return s.indicates
+	return s.indicates.Values()
 }
 
+// PeriodSatisfaction is true when candles of period are updated, it does not depend on history size
 func (s *sma) PeriodSatisfaction() bool {
-	return len(s.indicates) > s.period
+	return s.closes.full()
 }
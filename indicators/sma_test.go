@@ -44,3 +44,12 @@ func TestNewIndicate_SMA(t *testing.T) {
 	s.Calculate(c)
 	assert.Len(t, s.Get(), 9)
 }
+
+func TestSma_PeriodSatisfaction(t *testing.T) {
+	s := NewSma(3, WithHistory(1)).(*sma)
+	for i := 1; i <= 3; i++ {
+		assert.False(t, s.PeriodSatisfaction())
+		s.Update(container.Candle{Close: float64(i), Date: time.Now()})
+	}
+	assert.True(t, s.PeriodSatisfaction())
+}
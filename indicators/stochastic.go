@@ -0,0 +1,86 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package indicators
+
+import (
+	"time"
+
+	"github.com/gobenpark/trader/container"
+)
+
+// Stochastic is stochastic oscillator, K is position of close in high and low of period
+// and D is simple average of K, K of slow stochastic is simple average of fast K of slowing
+// K is 50 when high and low of period are same, K and D are pushed together after D period is satisfied
+type Stochastic struct {
+	high *extreme
+	low  *extreme
+	k    *window
+	d    *window
+	last time.Time
+	K    *History
+	D    *History
+}
+
+// NewStochastic is fast stochastic of K period and D period
+func NewStochastic(period, dPeriod int, opts ...Option) *Stochastic {
+	return NewSlowStochastic(period, 1, dPeriod, opts...)
+}
+
+// NewSlowStochastic is slow stochastic which K is smoothed by slowing period
+func NewSlowStochastic(period, slowing, dPeriod int, opts ...Option) *Stochastic {
+	c := newConfig(opts...)
+	return &Stochastic{
+		high: newExtreme(period, func(a, b float64) bool { return a > b }),
+		low:  newExtreme(period, func(a, b float64) bool { return a < b }),
+		k:    newWindow(slowing),
+		d:    newWindow(dPeriod),
+		K:    NewHistory(c.history),
+		D:    NewHistory(c.history),
+	}
+}
+
+func (s *Stochastic) Update(candle container.Candle) {
+	s.last = candle.Date
+	high, ok := s.high.next(candle.High)
+	low, _ := s.low.next(candle.Low)
+	if !ok {
+		return
+	}
+
+	k := 50.0
+	if high != low {
+		k = 100 * (candle.Close - low) / (high - low)
+	}
+	if s.k.push(k); !s.k.full() {
+		return
+	}
+	k = s.k.mean()
+
+	if s.d.push(k); !s.d.full() {
+		return
+	}
+	s.K.Push(Indicate{Data: k, Date: candle.Date})
+	s.D.Push(Indicate{Data: s.d.mean(), Date: candle.Date})
+}
+
+func (s *Stochastic) Calculate(c container.Container) {
+	calculate(c, s.last, s.Update)
+}
+
+// Get is K line
+func (s *Stochastic) Get() []Indicate {
+	return s.K.Values()
+}
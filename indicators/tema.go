@@ -0,0 +1,66 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package indicators
+
+import (
+	"time"
+
+	"github.com/gobenpark/trader/container"
+)
+
+// tema is triple exponential moving average, 3*ema - 3*ema(ema) + ema(ema(ema))
+type tema struct {
+	ema1      *emaCalc
+	ema2      *emaCalc
+	ema3      *emaCalc
+	last      time.Time
+	indicates *History
+}
+
+func NewTema(period int, opts ...Option) Indicator {
+	c := newConfig(opts...)
+	return &tema{
+		ema1:      newEmaCalc(period),
+		ema2:      newEmaCalc(period),
+		ema3:      newEmaCalc(period),
+		indicates: NewHistory(c.history),
+	}
+}
+
+func (t *tema) Update(candle container.Candle) {
+	t.last = candle.Date
+	v1, ok := t.ema1.next(candle.Close)
+	if !ok {
+		return
+	}
+	v2, ok := t.ema2.next(v1)
+	if !ok {
+		return
+	}
+	v3, ok := t.ema3.next(v2)
+	if !ok {
+		return
+	}
+	t.indicates.Push(Indicate{Data: 3*v1 - 3*v2 + v3, Date: candle.Date})
+}
+
+func (t *tema) Calculate(container container.Container) {
+	calculate(container, t.last, t.Update)
+}
+
+func (t *tema) Get() []Indicate {
+	return t.indicates.Values()
+}
@@ -0,0 +1,63 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package indicators
+
+import (
+	"math"
+	"time"
+
+	"github.com/gobenpark/trader/container"
+)
+
+// trCalc is true range of candle, high-low of first candle
+type trCalc struct {
+	prev float64
+	init bool
+}
+
+func (t *trCalc) next(candle container.Candle) float64 {
+	tr := candle.High - candle.Low
+	if t.init {
+		tr = math.Max(tr, math.Max(math.Abs(candle.High-t.prev), math.Abs(candle.Low-t.prev)))
+	}
+	t.prev = candle.Close
+	t.init = true
+	return tr
+}
+
+type trueRange struct {
+	calc      trCalc
+	last      time.Time
+	indicates *History
+}
+
+func NewTrueRange(opts ...Option) Indicator {
+	c := newConfig(opts...)
+	return &trueRange{indicates: NewHistory(c.history)}
+}
+
+func (t *trueRange) Update(candle container.Candle) {
+	t.last = candle.Date
+	t.indicates.Push(Indicate{Data: t.calc.next(candle), Date: candle.Date})
+}
+
+func (t *trueRange) Calculate(container container.Container) {
+	calculate(container, t.last, t.Update)
+}
+
+func (t *trueRange) Get() []Indicate {
+	return t.indicates.Values()
+}
@@ -0,0 +1,70 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package indicators
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVolatility(t *testing.T) {
+	tests := []struct {
+		name      string
+		indicator Indicator
+		result    string
+	}{
+		{"true range", NewTrueRange(), "tr_result.csv"},
+		{"atr", NewAtr(14), "atr_result.csv"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.indicator.Calculate(sampleContainer(t))
+			assertResult(t, readResult(t, test.result), test.indicator.Get())
+		})
+	}
+}
+
+func TestBand(t *testing.T) {
+	tests := []struct {
+		name   string
+		band   Band
+		result string
+	}{
+		{"keltner", NewKeltner(20, 10, 2), "keltner_result.csv"},
+		{"donchian", NewDonchian(20), "donchian_result.csv"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.band.Calculate(sampleContainer(t))
+			top, mid, bottom := test.band.Bands()
+			assertResult(t, readColumn(t, test.result, 1), top.Values())
+			assertResult(t, readColumn(t, test.result, 2), mid.Values())
+			assertResult(t, readColumn(t, test.result, 3), bottom.Values())
+			assert.Equal(t, mid.Values(), test.band.Get())
+		})
+	}
+
+	t.Run("bollinger band", func(t *testing.T) {
+		var b Band = NewBollingerBand(20)
+		b.Calculate(sampleContainer(t))
+		_, mid, _ := b.Bands()
+		assert.NotEmpty(t, b.Get())
+		assert.Equal(t, mid.Values(), b.Get())
+	})
+}
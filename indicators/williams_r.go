@@ -0,0 +1,62 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package indicators
+
+import (
+	"time"
+
+	"github.com/gobenpark/trader/container"
+)
+
+// williamsR is williams %R between -100 and 0, -50 when high and low of period are same
+type williamsR struct {
+	high      *extreme
+	low       *extreme
+	last      time.Time
+	indicates *History
+}
+
+func NewWilliamsR(period int, opts ...Option) Indicator {
+	c := newConfig(opts...)
+	return &williamsR{
+		high:      newExtreme(period, func(a, b float64) bool { return a > b }),
+		low:       newExtreme(period, func(a, b float64) bool { return a < b }),
+		indicates: NewHistory(c.history),
+	}
+}
+
+func (w *williamsR) Update(candle container.Candle) {
+	w.last = candle.Date
+	high, ok := w.high.next(candle.High)
+	low, _ := w.low.next(candle.Low)
+	if !ok {
+		return
+	}
+
+	r := -50.0
+	if high != low {
+		r = -100 * (high - candle.Close) / (high - low)
+	}
+	w.indicates.Push(Indicate{Data: r, Date: candle.Date})
+}
+
+func (w *williamsR) Calculate(container container.Container) {
+	calculate(container, w.last, w.Update)
+}
+
+func (w *williamsR) Get() []Indicate {
+	return w.indicates.Values()
+}
@@ -0,0 +1,75 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package indicators
+
+import (
+	"time"
+
+	"github.com/gobenpark/trader/container"
+)
+
+// wmaCalc is weighted moving average of values, latest value has weight period and oldest has 1
+type wmaCalc struct {
+	values   *window
+	weighted float64
+}
+
+func newWmaCalc(period int) *wmaCalc {
+	return &wmaCalc{values: newWindow(period)}
+}
+
+// next apply value and return average if period is satisfied
+func (w *wmaCalc) next(v float64) (float64, bool) {
+	n := float64(len(w.values.buf))
+	sum := w.values.sum
+	if _, full := w.values.push(v); full {
+		// weight of every value is decreased by one and oldest one is dropped
+		w.weighted += n*v - sum
+	} else {
+		w.weighted += float64(w.values.size) * v
+	}
+
+	if !w.values.full() {
+		return 0, false
+	}
+	return w.weighted / (n * (n + 1) / 2), true
+}
+
+type wma struct {
+	calc      *wmaCalc
+	last      time.Time
+	indicates *History
+}
+
+func NewWma(period int, opts ...Option) Indicator {
+	c := newConfig(opts...)
+	return &wma{calc: newWmaCalc(period), indicates: NewHistory(c.history)}
+}
+
+func (w *wma) Update(candle container.Candle) {
+	w.last = candle.Date
+	if v, ok := w.calc.next(candle.Close); ok {
+		w.indicates.Push(Indicate{Data: v, Date: candle.Date})
+	}
+}
+
+func (w *wma) Calculate(container container.Container) {
+	calculate(container, w.last, w.Update)
+}
+
+func (w *wma) Get() []Indicate {
+	return w.indicates.Values()
+}
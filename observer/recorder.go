@@ -0,0 +1,222 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package observer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gobenpark/trader/container"
+)
+
+var (
+	tickHeader   = []string{"Code", "Datetime", "Price", "Volume", "AskBid"}
+	candleHeader = []string{"Code", "Open", "High", "Low", "Close", "Volume", "Datetime"}
+)
+
+// Recorder write every tick and candle to csv files which can be loaded by file store
+// files are rotated by code and day of data time, tick file is dir/tick/code/2006-01-02.csv
+// and candle file is dir/candle/bar/code/2006-01-02.csv, bar is like 1m0s or volume-1000
+// files are opened in append mode and every row is written without buffer, so restart does not lose data
+type Recorder struct {
+	mu    sync.Mutex
+	dir   string
+	files map[string]*record
+	sync  bool
+	err   error
+}
+
+// record is opened file of one stream
+type record struct {
+	day  string
+	file *os.File
+}
+
+type RecorderOption func(*Recorder)
+
+// WithSync flush file to disk after every row, it is safe with power failure but slow
+func WithSync(b bool) RecorderOption {
+	return func(r *Recorder) {
+		r.sync = b
+	}
+}
+
+func NewRecorder(dir string, opts ...RecorderOption) *Recorder {
+	r := &Recorder{dir: dir, files: make(map[string]*record)}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Next record tick, so recorder can be used as Observer
+func (r *Recorder) Next(tick container.Tick) {
+	r.write(filepath.Join("tick", clean(tick.Code)), tick.Date, tickHeader, []string{
+		tick.Code,
+		tick.Date.Format(time.RFC3339Nano),
+		format(tick.Price),
+		format(tick.Volume),
+		tick.AskBid,
+	})
+}
+
+// Candle record candle of bar spec
+func (r *Recorder) Candle(spec container.BarSpec, candle container.Candle) {
+	r.write(filepath.Join("candle", clean(spec.String()), clean(candle.Code)), candle.Date, candleHeader, []string{
+		candle.Code,
+		format(candle.Open),
+		format(candle.High),
+		format(candle.Low),
+		format(candle.Close),
+		format(candle.Volume),
+		candle.Date.Format(time.RFC3339Nano),
+	})
+}
+
+// Err is first error of writing, recording is continued with other files after error
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// Close close every opened file
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var err error
+	for k, f := range r.files {
+		if e := f.file.Close(); e != nil && err == nil {
+			err = e
+		}
+		delete(r.files, k)
+	}
+	return err
+}
+
+func (r *Recorder) write(stream string, date time.Time, header, row []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.writeRow(stream, date.UTC().Format("2006-01-02"), header, row); err != nil && r.err == nil {
+		r.err = err
+	}
+}
+
+func (r *Recorder) writeRow(stream, day string, header, row []string) error {
+	rec, ok := r.files[stream]
+	if !ok || rec.day != day {
+		if ok {
+			rec.file.Close()
+			delete(r.files, stream)
+		}
+
+		f, err := open(filepath.Join(r.dir, stream, day+".csv"), header)
+		if err != nil {
+			return err
+		}
+		rec = &record{day: day, file: f}
+		r.files[stream] = rec
+	}
+
+	w := csv.NewWriter(rec.file)
+	if err := w.Write(row); err != nil {
+		return err
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	if r.sync {
+		return rec.file.Sync()
+	}
+	return nil
+}
+
+// open file in append mode, header is written to new file
+// if last row is broken by crash, it is truncated so file is always valid csv
+func open(path string, header []string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := repair(f, header); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+func repair(f *os.File, header []string) error {
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	size := info.Size()
+	tail := make([]byte, 4096)
+	for size > 0 {
+		offset := size - int64(len(tail))
+		if offset < 0 {
+			offset = 0
+		}
+		n, err := f.ReadAt(tail[:size-offset], offset)
+		if err != nil && err != io.EOF {
+			return err
+		}
+
+		if i := bytes.LastIndexByte(tail[:n], '\n'); i >= 0 {
+			size = offset + int64(i) + 1
+			break
+		}
+		size = offset
+	}
+
+	if size != info.Size() {
+		if err := f.Truncate(size); err != nil {
+			return err
+		}
+	}
+
+	if size == 0 {
+		w := csv.NewWriter(f)
+		w.Write(header)
+		w.Flush()
+		return w.Error()
+	}
+	return nil
+}
+
+// clean make code safe for file name
+func clean(code string) string {
+	return strings.NewReplacer("/", "_", "\\", "_", "..", "_").Replace(code)
+}
+
+func format(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
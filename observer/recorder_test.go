@@ -0,0 +1,86 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package observer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gobenpark/trader/container"
+	"github.com/gobenpark/trader/store/file"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ Observer = (*Recorder)(nil)
+
+func TestRecorder(t *testing.T) {
+	dir := t.TempDir()
+	day := time.Date(2021, 3, 20, 23, 59, 59, 500, time.UTC)
+
+	r := NewRecorder(dir)
+	r.Next(container.Tick{Code: "KRW-BTC", AskBid: "ASK", Date: day, Price: 100.5, Volume: 1})
+	r.Next(container.Tick{Code: "KRW-ETH", AskBid: "BID", Date: day, Price: 10, Volume: 2})
+	r.Next(container.Tick{Code: "KRW-BTC", AskBid: "BID", Date: day.Add(time.Second), Price: 101, Volume: 3})
+	r.Candle(container.TimeBar(time.Minute), container.Candle{Code: "KRW-BTC", Open: 1, High: 2, Low: 0.5, Close: 1.5, Volume: 10, Date: day})
+	require.NoError(t, r.Err())
+	require.NoError(t, r.Close())
+
+	data, err := os.ReadFile(filepath.Join(dir, "tick", "KRW-BTC", "2021-03-20.csv"))
+	require.NoError(t, err)
+	assert.Equal(t, "Code,Datetime,Price,Volume,AskBid\nKRW-BTC,2021-03-20T23:59:59.0000005Z,100.5,1,ASK\n", string(data))
+	assert.FileExists(t, filepath.Join(dir, "tick", "KRW-BTC", "2021-03-21.csv"))
+	assert.FileExists(t, filepath.Join(dir, "tick", "KRW-ETH", "2021-03-20.csv"))
+
+	s := file.NewStore(0, 0, file.WithCandleFile("KRW-BTC", time.Minute, filepath.Join(dir, "candle", "1m0s", "KRW-BTC", "2021-03-20.csv")))
+	candles, err := s.LoadHistory(context.Background(), "KRW-BTC", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, []container.Candle{{Code: "KRW-BTC", Open: 1, High: 2, Low: 0.5, Close: 1.5, Volume: 10, Date: day}}, candles)
+}
+
+func TestRecorder_Restart(t *testing.T) {
+	dir := t.TempDir()
+	day := time.Date(2021, 3, 20, 0, 0, 0, 0, time.UTC)
+	path := filepath.Join(dir, "tick", "code", "2021-03-20.csv")
+
+	r := NewRecorder(dir, WithSync(true))
+	r.Next(container.Tick{Code: "code", Date: day, Price: 1, Volume: 1})
+	require.NoError(t, r.Close())
+
+	// crash in the middle of row
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString("code,2021-03-20T00:00:0")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	r = NewRecorder(dir)
+	r.Next(container.Tick{Code: "code", Date: day.Add(time.Second), Price: 2, Volume: 1})
+	require.NoError(t, r.Close())
+
+	s := file.NewStore(0, 0, file.WithTickFile("code", path))
+	ch, err := s.LoadTick(context.Background(), "code")
+	require.NoError(t, err)
+
+	var prices []float64
+	for tick := range ch {
+		prices = append(prices, tick.Price)
+	}
+	assert.Equal(t, []float64{1, 2}, prices)
+}
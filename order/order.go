@@ -54,14 +54,68 @@ type Order struct {
 	status Status
 	OType
 	ExecType
-	Code       string    `json:"code"`
-	UUID       string    `json:"uuid"`
-	Size       int64     `json:"size"`
-	Price      float64   `json:"price"`
+	Code  string  `json:"code"`
+	UUID  string  `json:"uuid"`
+	Size  int64   `json:"size"`
+	Price float64 `json:"price"`
+	// PriceLimit is limit price of StopLimit and StopTrailLimit after stop price is triggered
+	PriceLimit float64 `json:"priceLimit"`
+	// TrailAmount, TrailPercent is distance of trailing stop price from best price
+	TrailAmount  float64 `json:"trailAmount"`
+	TrailPercent float64 `json:"trailPercent"`
+	// Parent is uuid of parent order if order is child of bracket order
+	Parent     string    `json:"parent"`
 	CreatedAt  time.Time `json:"createdAt"`
 	ExecutedAt time.Time `json:"executedAt"`
-	mu         sync.RWMutex
-	StoreUID   string `json:"-"`
+	// ExecutedSize total size of fills, ExecutedPrice is volume weighted average price of fills
+	ExecutedSize  int64   `json:"executedSize"`
+	ExecutedPrice float64 `json:"executedPrice"`
+	Fills         []Fill  `json:"fills"`
+	mu            sync.RWMutex
+	StoreUID      string `json:"-"`
+}
+
+// Fill is single execution of order
+type Fill struct {
+	Size  int64     `json:"size"`
+	Price float64   `json:"price"`
+	Date  time.Time `json:"date"`
+}
+
+// Option is additional setting of order
+type Option func(*Order)
+
+func WithPriceLimit(price float64) Option {
+	return func(o *Order) {
+		o.PriceLimit = price
+	}
+}
+
+func WithTrailAmount(amount float64) Option {
+	return func(o *Order) {
+		o.TrailAmount = amount
+	}
+}
+
+// WithTrailPercent percent is ratio, 0.05 means 5%
+func WithTrailPercent(percent float64) Option {
+	return func(o *Order) {
+		o.TrailPercent = percent
+	}
+}
+
+// IsStop is true if order is managed by stop price
+func (e ExecType) IsStop() bool {
+	switch e {
+	case Stop, StopLimit, StopTrail, StopTrailLimit:
+		return true
+	}
+	return false
+}
+
+// IsTrail is true if stop price follow the market price
+func (e ExecType) IsTrail() bool {
+	return e == StopTrail || e == StopTrailLimit
 }
 
 func (o *Order) Reject(err error) {
@@ -107,6 +161,38 @@ func (o *Order) Submit() {
 	o.ExecutedAt = time.Now()
 }
 
+// Execute add fill to order, order is completed if whole size is executed otherwise partial
+func (o *Order) Execute(size int64, price float64, date time.Time) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	total := float64(o.ExecutedSize)*o.ExecutedPrice + float64(size)*price
+	o.ExecutedSize += size
+	if o.ExecutedSize != 0 {
+		o.ExecutedPrice = total / float64(o.ExecutedSize)
+	}
+	o.Fills = append(o.Fills, Fill{Size: size, Price: price, Date: date})
+	o.ExecutedAt = date
+
+	o.status = Partial
+	if o.ExecutedSize >= o.Size {
+		o.status = Completed
+	}
+}
+
+// Resize change whole size of order, executed size is kept
+func (o *Order) Resize(size int64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.Size = size
+}
+
+// Remaining is size which is not executed yet
+func (o *Order) Remaining() int64 {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.Size - o.ExecutedSize
+}
+
 func (o *Order) Complete() {
 	o.mu.Lock()
 	defer o.mu.Unlock()
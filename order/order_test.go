@@ -0,0 +1,50 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package order
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrder_Execute(t *testing.T) {
+	o := &Order{OType: Buy, ExecType: Market, Code: "code", UUID: "uuid", Size: 10}
+	o.Submit()
+
+	now := time.Now()
+	o.Execute(4, 100, now)
+	assert.Equal(t, Partial, o.Status())
+	assert.Equal(t, int64(6), o.Remaining())
+	assert.Equal(t, float64(100), o.ExecutedPrice)
+	assert.Equal(t, now, o.ExecutedAt)
+
+	o.Execute(6, 110, now.Add(time.Minute))
+	assert.Equal(t, Completed, o.Status())
+	assert.Equal(t, int64(0), o.Remaining())
+	assert.Equal(t, int64(10), o.ExecutedSize)
+	assert.Equal(t, float64(106), o.ExecutedPrice)
+	assert.Len(t, o.Fills, 2)
+	assert.Equal(t, Fill{Size: 6, Price: 110, Date: now.Add(time.Minute)}, o.Fills[1])
+}
+
+func TestExecType_IsStop(t *testing.T) {
+	assert.True(t, StopTrailLimit.IsStop())
+	assert.True(t, StopTrail.IsTrail())
+	assert.False(t, Stop.IsTrail())
+	assert.False(t, Limit.IsStop())
+}
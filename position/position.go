@@ -15,11 +15,80 @@
  */
 package position
 
-import "time"
+import (
+	"math"
+	"time"
+)
 
+// Position is net holding of code
+// Size is positive for long and negative for short, Price is volume weighted average entry price
 type Position struct {
-	Code      string    `json:"code"`
-	Size      int64     `json:"size"`
-	Price     float64   `json:"price"`
-	CreatedAt time.Time `json:"createdAt"`
+	Code  string  `json:"code"`
+	Size  int64   `json:"size"`
+	Price float64 `json:"price"`
+	// MarketPrice latest price which position is marked
+	MarketPrice float64 `json:"marketPrice"`
+	// RealizedPnL profit of reduced size without commission
+	RealizedPnL   float64 `json:"realizedPnl"`
+	UnrealizedPnL float64 `json:"unrealizedPnl"`
+	// Commission total commission paid for code
+	Commission float64   `json:"commission"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// Update apply executed size, size is positive for buy and negative for sell
+// increasing size change average price, and reducing size realize profit with average price
+// if size flip long and short, remain size open new position with price
+// it returns realized profit of this execution
+func (p *Position) Update(size int64, price, commission float64, date time.Time) float64 {
+	realized := 0.0
+	current := math.Abs(float64(p.Size))
+	amount := math.Abs(float64(size))
+
+	switch {
+	case p.Size == 0:
+		p.Price = price
+		p.CreatedAt = date
+	case (p.Size > 0) == (size > 0):
+		p.Price = (p.Price*current + price*amount) / (current + amount)
+	default:
+		closed := math.Min(current, amount)
+		if p.Size > 0 {
+			realized = (price - p.Price) * closed
+		} else {
+			realized = (p.Price - price) * closed
+		}
+
+		if amount > current {
+			p.Price = price
+			p.CreatedAt = date
+		}
+	}
+
+	p.Size += size
+	if p.Size == 0 {
+		p.Price = 0
+	}
+	p.RealizedPnL += realized
+	p.Commission += commission
+	p.UpdatedAt = date
+	p.Mark(price)
+	return realized
+}
+
+// Mark evaluate position with market price
+func (p *Position) Mark(price float64) {
+	p.MarketPrice = price
+	p.UnrealizedPnL = (price - p.Price) * float64(p.Size)
+}
+
+// Value is market value of position, negative for short
+func (p Position) Value() float64 {
+	return p.MarketPrice * float64(p.Size)
+}
+
+// PnL is total profit of code with commission
+func (p Position) PnL() float64 {
+	return p.RealizedPnL + p.UnrealizedPnL - p.Commission
 }
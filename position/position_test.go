@@ -0,0 +1,104 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package position
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPosition_Update(t *testing.T) {
+	type execution struct {
+		size  int64
+		price float64
+	}
+
+	tests := []struct {
+		name       string
+		executions []execution
+		size       int64
+		price      float64
+		realized   float64
+	}{
+		{
+			"open long",
+			[]execution{{10, 100}},
+			10,
+			100,
+			0,
+		},
+		{
+			"average price",
+			[]execution{{10, 100}, {30, 200}},
+			40,
+			175,
+			0,
+		},
+		{
+			"reduce long",
+			[]execution{{10, 100}, {-4, 150}},
+			6,
+			100,
+			200,
+		},
+		{
+			"close long",
+			[]execution{{10, 100}, {-10, 90}},
+			0,
+			0,
+			-100,
+		},
+		{
+			"flip long to short",
+			[]execution{{10, 100}, {-15, 120}},
+			-5,
+			120,
+			200,
+		},
+		{
+			"reduce short",
+			[]execution{{-10, 100}, {5, 80}},
+			-5,
+			100,
+			100,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := Position{Code: "code"}
+			for _, e := range test.executions {
+				p.Update(e.size, e.price, 1, time.Now())
+			}
+			assert.Equal(t, test.size, p.Size)
+			assert.Equal(t, test.price, p.Price)
+			assert.Equal(t, test.realized, p.RealizedPnL)
+			assert.Equal(t, float64(len(test.executions)), p.Commission)
+		})
+	}
+}
+
+func TestPosition_Mark(t *testing.T) {
+	p := Position{Code: "code"}
+	p.Update(-10, 100, 5, time.Now())
+	p.Mark(90)
+
+	assert.Equal(t, float64(100), p.UnrealizedPnL)
+	assert.Equal(t, float64(-900), p.Value())
+	assert.Equal(t, float64(95), p.PnL())
+}
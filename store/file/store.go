@@ -0,0 +1,338 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+// file package load market data from csv files for offline backtest
+package file
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gobenpark/trader/container"
+	error2 "github.com/gobenpark/trader/error"
+	"github.com/gobenpark/trader/store/simulator"
+)
+
+// Columns is header name of each field in csv file
+// Code column is optional, if file does not have it every row belongs to code of file
+type Columns struct {
+	Code   string
+	Open   string
+	High   string
+	Low    string
+	Close  string
+	Volume string
+	Date   string
+	Price  string
+	AskBid string
+}
+
+// DefaultColumns is header of indicators/ticksample.csv
+var DefaultColumns = Columns{
+	Code:   "Code",
+	Open:   "Open",
+	High:   "High",
+	Low:    "Low",
+	Close:  "Close",
+	Volume: "Volume",
+	Date:   "Datetime",
+	Price:  "Price",
+	AskBid: "AskBid",
+}
+
+type candleFile struct {
+	code  string
+	level time.Duration
+}
+
+// Store load candles and ticks from csv files
+// orders are executed in embedded simulator exchange, so it can be used directly for backtest
+type Store struct {
+	*simulator.Exchange
+	candles    map[candleFile]string
+	ticks      map[string]string
+	columns    Columns
+	timeFormat string
+	location   *time.Location
+	exchange   []simulator.Option
+
+	mu sync.Mutex
+	// err is first error which stopped replaying tick file
+	err error
+}
+
+type Option func(*Store)
+
+// WithCandleFile set csv file of candles which level is d
+// several codes can share one file if it has code column
+func WithCandleFile(code string, d time.Duration, path string) Option {
+	return func(s *Store) {
+		s.candles[candleFile{code: code, level: d}] = path
+	}
+}
+
+// WithTickFile set csv file of ticks, rows are replayed by LoadTick
+func WithTickFile(code string, path string) Option {
+	return func(s *Store) {
+		s.ticks[code] = path
+	}
+}
+
+// WithColumns change header names, empty name of columns keep default
+func WithColumns(c Columns) Option {
+	return func(s *Store) {
+		for _, i := range []struct {
+			dst *string
+			src string
+		}{
+			{&s.columns.Code, c.Code},
+			{&s.columns.Open, c.Open},
+			{&s.columns.High, c.High},
+			{&s.columns.Low, c.Low},
+			{&s.columns.Close, c.Close},
+			{&s.columns.Volume, c.Volume},
+			{&s.columns.Date, c.Date},
+			{&s.columns.Price, c.Price},
+			{&s.columns.AskBid, c.AskBid},
+		} {
+			if i.src != "" {
+				*i.dst = i.src
+			}
+		}
+	}
+}
+
+// WithTimeFormat set layout of date column, default is time.RFC3339
+func WithTimeFormat(layout string) Option {
+	return func(s *Store) {
+		s.timeFormat = layout
+	}
+}
+
+// WithLocation set location of date which layout does not have time zone, default is UTC
+func WithLocation(loc *time.Location) Option {
+	return func(s *Store) {
+		s.location = loc
+	}
+}
+
+// WithExchangeOption set options of simulator exchange like volume limit
+func WithExchangeOption(opts ...simulator.Option) Option {
+	return func(s *Store) {
+		s.exchange = append(s.exchange, opts...)
+	}
+}
+
+// NewStore create csv store which execute orders with cash and commission rate
+func NewStore(cash int64, commission float64, opts ...Option) *Store {
+	s := &Store{
+		candles:    make(map[candleFile]string),
+		ticks:      make(map[string]string),
+		columns:    DefaultColumns,
+		timeFormat: time.RFC3339,
+		location:   time.UTC,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.Exchange = simulator.NewExchange(cash, commission, s.exchange...)
+	return s
+}
+
+// LoadHistory read candles of code from file of level d in order of date
+func (s *Store) LoadHistory(ctx context.Context, code string, d time.Duration) ([]container.Candle, error) {
+	path, ok := s.candles[candleFile{code: code, level: d}]
+	if !ok {
+		return nil, error2.ErrNotExistData
+	}
+
+	var candles []container.Candle
+	required := []string{s.columns.Open, s.columns.High, s.columns.Low, s.columns.Close, s.columns.Volume}
+	err := s.read(ctx, path, code, required, func(r row) error {
+		c := container.Candle{Code: code}
+		var err error
+		if c.Date, err = r.date(); err != nil {
+			return err
+		}
+		for _, i := range []struct {
+			dst *float64
+			col string
+		}{
+			{&c.Open, s.columns.Open},
+			{&c.High, s.columns.High},
+			{&c.Low, s.columns.Low},
+			{&c.Close, s.columns.Close},
+			{&c.Volume, s.columns.Volume},
+		} {
+			if *i.dst, err = r.float(i.col); err != nil {
+				return err
+			}
+		}
+		candles = append(candles, c)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(candles, func(i, j int) bool {
+		return candles[i].Date.Before(candles[j].Date)
+	})
+	return candles, nil
+}
+
+// LoadTick replay ticks of code in order of rows, channel is closed at the end of file
+// if bad row stop replaying, channel is closed early and its error is kept in Err
+func (s *Store) LoadTick(ctx context.Context, code string) (<-chan container.Tick, error) {
+	path, ok := s.ticks[code]
+	if !ok {
+		return nil, error2.ErrNotExistData
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+
+	ch := make(chan container.Tick)
+	go func() {
+		defer close(ch)
+		err := s.read(ctx, path, code, []string{s.columns.Price, s.columns.Volume}, func(r row) error {
+			t := container.Tick{Code: code, AskBid: r.get(s.columns.AskBid)}
+			var err error
+			if t.Date, err = r.date(); err != nil {
+				return err
+			}
+			if t.Price, err = r.float(s.columns.Price); err != nil {
+				return err
+			}
+			if t.Volume, err = r.float(s.columns.Volume); err != nil {
+				return err
+			}
+
+			select {
+			case ch <- t:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		if err != nil && ctx.Err() == nil {
+			s.setErr(fmt.Errorf("%s: %w", path, err))
+		}
+	}()
+	return ch, nil
+}
+
+func (s *Store) setErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+// Err is first error which stopped replaying tick file, nil if every tick is replayed or it is canceled
+// check it after tick channel is closed
+func (s *Store) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// row is one line of csv file
+type row struct {
+	s      *Store
+	header map[string]int
+	values []string
+}
+
+// get is value of column, empty if file does not have the column
+func (r row) get(col string) string {
+	if i, ok := r.header[col]; ok && i < len(r.values) {
+		return strings.TrimSpace(r.values[i])
+	}
+	return ""
+}
+
+// float is number of column, zero if value of row is empty
+func (r row) float(col string) (float64, error) {
+	v := r.get(col)
+	if v == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(v, 64)
+}
+
+func (r row) date() (time.Time, error) {
+	return time.ParseInLocation(r.s.timeFormat, r.get(r.s.columns.Date), r.s.location)
+}
+
+// read call fn with every row of code in file
+// file must have date column and every required column in its header
+func (s *Store) read(ctx context.Context, path, code string, required []string, fn func(r row) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1
+	head, err := reader.Read()
+	if err != nil {
+		return err
+	}
+
+	r := row{s: s, header: make(map[string]int)}
+	for k, v := range head {
+		r.header[strings.TrimSpace(v)] = k
+	}
+	for _, col := range append([]string{s.columns.Date}, required...) {
+		if _, ok := r.header[col]; !ok {
+			return fmt.Errorf("%s: header does not have column %q: %w", path, col, error2.ErrNotExistData)
+		}
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		values, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		r.values = values
+		if c := r.get(s.columns.Code); c != "" && c != code {
+			continue
+		}
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+}
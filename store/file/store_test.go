@@ -0,0 +1,163 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package file
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gobenpark/trader/container"
+	error2 "github.com/gobenpark/trader/error"
+	"github.com/gobenpark/trader/order"
+	"github.com/gobenpark/trader/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ store.Simulator = (*Store)(nil)
+
+func write(t *testing.T, data string) string {
+	path := filepath.Join(t.TempDir(), "data.csv")
+	require.NoError(t, os.WriteFile(path, []byte(data), 0644))
+	return path
+}
+
+func TestStore_LoadHistory(t *testing.T) {
+	s := NewStore(0, 0, WithCandleFile("KRW-WAXP", 3*time.Minute, "../../indicators/ticksample.csv"))
+
+	candles, err := s.LoadHistory(context.Background(), "KRW-WAXP", 3*time.Minute)
+	require.NoError(t, err)
+	assert.Len(t, candles, 200)
+	assert.Equal(t, container.Candle{
+		Code:   "KRW-WAXP",
+		Open:   262,
+		High:   263,
+		Low:    262,
+		Close:  263,
+		Volume: 62910.41056777,
+		Date:   time.Date(2021, 3, 20, 17, 45, 0, 0, time.UTC),
+	}, candles[0])
+
+	_, err = s.LoadHistory(context.Background(), "KRW-WAXP", time.Minute)
+	assert.Equal(t, error2.ErrNotExistData, err)
+}
+
+func TestStore_Columns(t *testing.T) {
+	path := write(t, "symbol,time,o,h,l,c,v\n"+
+		"b,2021-01-01 09:02,1,1,1,1,1\n"+
+		"a,2021-01-01 09:01,2,4,1,3,5\n"+
+		"a,2021-01-01 09:00,1,2,1,2,\n")
+
+	loc := time.FixedZone("KST", 9*60*60)
+	s := NewStore(0, 0,
+		WithCandleFile("a", time.Minute, path),
+		WithColumns(Columns{Code: "symbol", Date: "time", Open: "o", High: "h", Low: "l", Close: "c", Volume: "v"}),
+		WithTimeFormat("2006-01-02 15:04"),
+		WithLocation(loc),
+	)
+
+	candles, err := s.LoadHistory(context.Background(), "a", time.Minute)
+	require.NoError(t, err)
+	require.Len(t, candles, 2)
+	assert.Equal(t, time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), candles[0].Date.UTC())
+	assert.Equal(t, float64(0), candles[0].Volume)
+	assert.Equal(t, container.Candle{Code: "a", Open: 2, High: 4, Low: 1, Close: 3, Volume: 5, Date: time.Date(2021, 1, 1, 9, 1, 0, 0, loc)}, candles[1])
+}
+
+func TestStore_MissingColumn(t *testing.T) {
+	candle := write(t, "Datetime,Open,High,Close,Volume\n"+
+		"2021-01-01T00:00:00Z,1,2,1,10\n")
+	s := NewStore(0, 0, WithCandleFile("code", time.Minute, candle))
+
+	_, err := s.LoadHistory(context.Background(), "code", time.Minute)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, error2.ErrNotExistData))
+	assert.Contains(t, err.Error(), `"Low"`)
+
+	tick := write(t, "Datetime,Volume\n"+
+		"2021-01-01T00:00:00Z,1\n")
+	s = NewStore(0, 0, WithTickFile("code", tick))
+
+	ch, err := s.LoadTick(context.Background(), "code")
+	require.NoError(t, err)
+	for range ch {
+		t.Fatal("tick of file without price column")
+	}
+	require.Error(t, s.Err())
+	assert.Contains(t, s.Err().Error(), `"Price"`)
+}
+
+func TestStore_LoadTick(t *testing.T) {
+	path := write(t, "Datetime,Price,Volume,AskBid\n"+
+		"2021-01-01T00:00:00Z,100,1,ASK\n"+
+		"2021-01-01T00:00:01Z,101,2,BID\n")
+	s := NewStore(0, 0, WithTickFile("code", path))
+
+	ch, err := s.LoadTick(context.Background(), "code")
+	require.NoError(t, err)
+
+	var ticks []container.Tick
+	for tick := range ch {
+		ticks = append(ticks, tick)
+	}
+	assert.Equal(t, []container.Tick{
+		{Code: "code", AskBid: "ASK", Date: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), Price: 100, Volume: 1},
+		{Code: "code", AskBid: "BID", Date: time.Date(2021, 1, 1, 0, 0, 1, 0, time.UTC), Price: 101, Volume: 2},
+	}, ticks)
+
+	_, err = s.LoadTick(context.Background(), "other")
+	assert.Equal(t, error2.ErrNotExistData, err)
+	assert.NoError(t, s.Err())
+}
+
+func TestStore_LoadTickError(t *testing.T) {
+	path := write(t, "Datetime,Price,Volume,AskBid\n"+
+		"2021-01-01T00:00:00Z,100,1,ASK\n"+
+		"2021-01-01T00:00:01Z,abc,2,BID\n"+
+		"2021-01-01T00:00:02Z,102,3,ASK\n")
+	s := NewStore(0, 0, WithTickFile("code", path))
+
+	ch, err := s.LoadTick(context.Background(), "code")
+	require.NoError(t, err)
+
+	var ticks []container.Tick
+	for tick := range ch {
+		ticks = append(ticks, tick)
+	}
+
+	// replaying stop at bad row
+	assert.Len(t, ticks, 1)
+	require.Error(t, s.Err())
+	assert.Contains(t, s.Err().Error(), path)
+	assert.Contains(t, s.Err().Error(), "abc")
+}
+
+func TestStore_Order(t *testing.T) {
+	s := NewStore(1000, 0, WithCandleFile("KRW-WAXP", 3*time.Minute, "../../indicators/ticksample.csv"))
+	candles, err := s.LoadHistory(context.Background(), "KRW-WAXP", 3*time.Minute)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Order(&order.Order{OType: order.Buy, ExecType: order.Market, Code: "KRW-WAXP", UUID: "1", Size: 2}))
+	evts := s.Next(candles[0])
+	require.Len(t, evts, 1)
+	assert.Equal(t, "done", evts[0].State)
+	assert.Equal(t, int64(1000-2*262), s.Cash())
+	assert.Equal(t, int64(2), s.Positions()[0].Size)
+}
@@ -181,3 +181,208 @@ func (mr *MockStoreMockRecorder) OrderInfo(id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OrderInfo", reflect.TypeOf((*MockStore)(nil).OrderInfo), id)
 }
+
+// MockSimulator is a mock of Simulator interface
+type MockSimulator struct {
+	ctrl     *gomock.Controller
+	recorder *MockSimulatorMockRecorder
+}
+
+// MockSimulatorMockRecorder is the mock recorder for MockSimulator
+type MockSimulatorMockRecorder struct {
+	mock *MockSimulator
+}
+
+// NewMockSimulator creates a new mock instance
+func NewMockSimulator(ctrl *gomock.Controller) *MockSimulator {
+	mock := &MockSimulator{ctrl: ctrl}
+	mock.recorder = &MockSimulatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockSimulator) EXPECT() *MockSimulatorMockRecorder {
+	return m.recorder
+}
+
+// Order mocks base method
+func (m *MockSimulator) Order(o *order.Order) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Order", o)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Order indicates an expected call of Order
+func (mr *MockSimulatorMockRecorder) Order(o interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Order", reflect.TypeOf((*MockSimulator)(nil).Order), o)
+}
+
+// Cancel mocks base method
+func (m *MockSimulator) Cancel(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Cancel", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Cancel indicates an expected call of Cancel
+func (mr *MockSimulatorMockRecorder) Cancel(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cancel", reflect.TypeOf((*MockSimulator)(nil).Cancel), id)
+}
+
+// LoadHistory mocks base method
+func (m *MockSimulator) LoadHistory(ctx context.Context, code string, d time.Duration) ([]container.Candle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadHistory", ctx, code, d)
+	ret0, _ := ret[0].([]container.Candle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadHistory indicates an expected call of LoadHistory
+func (mr *MockSimulatorMockRecorder) LoadHistory(ctx, code, d interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadHistory", reflect.TypeOf((*MockSimulator)(nil).LoadHistory), ctx, code, d)
+}
+
+// LoadTick mocks base method
+func (m *MockSimulator) LoadTick(ctx context.Context, code string) (<-chan container.Tick, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadTick", ctx, code)
+	ret0, _ := ret[0].(<-chan container.Tick)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadTick indicates an expected call of LoadTick
+func (mr *MockSimulatorMockRecorder) LoadTick(ctx, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadTick", reflect.TypeOf((*MockSimulator)(nil).LoadTick), ctx, code)
+}
+
+// Uid mocks base method
+func (m *MockSimulator) Uid() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Uid")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Uid indicates an expected call of Uid
+func (mr *MockSimulatorMockRecorder) Uid() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Uid", reflect.TypeOf((*MockSimulator)(nil).Uid))
+}
+
+// Cash mocks base method
+func (m *MockSimulator) Cash() int64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Cash")
+	ret0, _ := ret[0].(int64)
+	return ret0
+}
+
+// Cash indicates an expected call of Cash
+func (mr *MockSimulatorMockRecorder) Cash() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cash", reflect.TypeOf((*MockSimulator)(nil).Cash))
+}
+
+// Commission mocks base method
+func (m *MockSimulator) Commission() float64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Commission")
+	ret0, _ := ret[0].(float64)
+	return ret0
+}
+
+// Commission indicates an expected call of Commission
+func (mr *MockSimulatorMockRecorder) Commission() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Commission", reflect.TypeOf((*MockSimulator)(nil).Commission))
+}
+
+// Positions mocks base method
+func (m *MockSimulator) Positions() []position.Position {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Positions")
+	ret0, _ := ret[0].([]position.Position)
+	return ret0
+}
+
+// Positions indicates an expected call of Positions
+func (mr *MockSimulatorMockRecorder) Positions() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Positions", reflect.TypeOf((*MockSimulator)(nil).Positions))
+}
+
+// OrderState mocks base method
+func (m *MockSimulator) OrderState(ctx context.Context) (<-chan event.OrderEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OrderState", ctx)
+	ret0, _ := ret[0].(<-chan event.OrderEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OrderState indicates an expected call of OrderState
+func (mr *MockSimulatorMockRecorder) OrderState(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OrderState", reflect.TypeOf((*MockSimulator)(nil).OrderState), ctx)
+}
+
+// OrderInfo mocks base method
+func (m *MockSimulator) OrderInfo(id string) (*order.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OrderInfo", id)
+	ret0, _ := ret[0].(*order.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OrderInfo indicates an expected call of OrderInfo
+func (mr *MockSimulatorMockRecorder) OrderInfo(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OrderInfo", reflect.TypeOf((*MockSimulator)(nil).OrderInfo), id)
+}
+
+// SetCash mocks base method
+func (m *MockSimulator) SetCash(cash int64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetCash", cash)
+}
+
+// SetCash indicates an expected call of SetCash
+func (mr *MockSimulatorMockRecorder) SetCash(cash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCash", reflect.TypeOf((*MockSimulator)(nil).SetCash), cash)
+}
+
+// SetCommission mocks base method
+func (m *MockSimulator) SetCommission(commission float64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetCommission", commission)
+}
+
+// SetCommission indicates an expected call of SetCommission
+func (mr *MockSimulatorMockRecorder) SetCommission(commission interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCommission", reflect.TypeOf((*MockSimulator)(nil).SetCommission), commission)
+}
+
+// Next mocks base method
+func (m *MockSimulator) Next(candle container.Candle) []event.OrderEvent {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Next", candle)
+	ret0, _ := ret[0].([]event.OrderEvent)
+	return ret0
+}
+
+// Next indicates an expected call of Next
+func (mr *MockSimulatorMockRecorder) Next(candle interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Next", reflect.TypeOf((*MockSimulator)(nil).Next), candle)
+}
@@ -0,0 +1,195 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package replay
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// clock is virtual time of replay
+// virtual time flows speed times faster than wall time from origin, and it stops while paused
+type clock struct {
+	mu    sync.Mutex
+	speed float64
+	// origin virtual time at wall time base
+	origin  time.Time
+	base    time.Time
+	started bool
+	paused  bool
+	// steps count of requested step which is not yet consumed
+	steps int
+	// waiting tick time of each waiting replay
+	waiting map[int]time.Time
+	seq     int
+	// changed is closed and replaced whenever state is changed
+	changed chan struct{}
+	wall    func() time.Time
+}
+
+func newClock(speed float64) *clock {
+	return &clock{
+		speed:   speed,
+		waiting: make(map[int]time.Time),
+		changed: make(chan struct{}),
+		wall:    time.Now,
+	}
+}
+
+// now is current virtual time, zero before first tick
+func (c *clock) now() time.Time {
+	if !c.started {
+		return time.Time{}
+	}
+	if c.paused || c.speed == 0 {
+		return c.origin
+	}
+	return c.origin.Add(time.Duration(float64(c.wall().Sub(c.base)) * c.speed))
+}
+
+func (c *clock) notify() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+// rebase fix virtual time to t from now
+func (c *clock) rebase(t time.Time) {
+	c.origin = t
+	c.base = c.wall()
+	c.started = true
+}
+
+// wait block until virtual time reach date, false if context is done
+func (c *clock) wait(ctx context.Context, date time.Time) bool {
+	c.mu.Lock()
+	c.seq++
+	id := c.seq
+	defer func() {
+		delete(c.waiting, id)
+		c.mu.Unlock()
+	}()
+
+	// replay start from first tick, but it is held if clock is paused before start
+	if !c.started && !c.paused {
+		c.rebase(date)
+	}
+
+	for {
+		now := c.now()
+		switch {
+		case !date.After(now):
+			return true
+		case c.paused && c.steps > 0:
+			c.steps--
+			c.origin = date
+			c.started = true
+			c.notify()
+			return true
+		case !c.paused && c.speed == 0:
+			c.origin = date
+			return true
+		}
+
+		c.waiting[id] = date
+		changed := c.changed
+		timer := time.NewTimer(time.Hour)
+		if !c.paused {
+			timer.Reset(time.Duration(float64(date.Sub(now)) / c.speed))
+		}
+
+		c.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			c.mu.Lock()
+			return false
+		case <-changed:
+		case <-timer.C:
+		}
+		timer.Stop()
+		c.mu.Lock()
+	}
+}
+
+func (c *clock) pause() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.paused {
+		return
+	}
+	if c.started {
+		c.origin = c.now()
+	}
+	c.paused = true
+	c.notify()
+}
+
+func (c *clock) resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.paused {
+		return
+	}
+	c.paused = false
+	c.steps = 0
+	if c.started {
+		c.rebase(c.origin)
+	}
+	c.notify()
+}
+
+// step release earliest waiting tick while paused
+// if no tick is waiting, next tick is released when it arrives
+func (c *clock) step() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.paused {
+		return
+	}
+
+	var next time.Time
+	for _, d := range c.waiting {
+		if next.IsZero() || d.Before(next) {
+			next = d
+		}
+	}
+	if next.IsZero() {
+		c.steps++
+		return
+	}
+	c.origin = next
+	c.started = true
+	c.notify()
+}
+
+// seek move virtual time to t, ticks before t are released without waiting
+func (c *clock) seek(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rebase(t)
+	c.notify()
+}
+
+func (c *clock) setSpeed(speed float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.started {
+		c.rebase(c.now())
+	}
+	c.speed = speed
+	c.notify()
+}
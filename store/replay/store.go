@@ -0,0 +1,122 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+// replay package replay recorded ticks with controlled speed for debugging live trading
+package replay
+
+import (
+	"context"
+	"time"
+
+	"github.com/gobenpark/trader/container"
+	"github.com/gobenpark/trader/store"
+	"github.com/gobenpark/trader/store/simulator"
+)
+
+// Store replay ticks of data store through LoadTick along virtual clock
+// every code share one clock, so ticks of codes are released in order of time
+// orders are executed locally, data store is wrapped with simulator if it is not simulator
+type Store struct {
+	store.Simulator
+	clock *clock
+}
+
+type Option func(*Store)
+
+// WithSpeed set replay speed, 1 is real time, 2 is twice faster and 0 is as fast as possible
+func WithSpeed(speed float64) Option {
+	return func(s *Store) {
+		s.clock.speed = speed
+	}
+}
+
+// WithPause start replay paused, ticks are released by Step or Resume
+func WithPause() Option {
+	return func(s *Store) {
+		s.clock.paused = true
+	}
+}
+
+// NewStore create replay store, default speed is real time
+func NewStore(data store.Store, opts ...Option) *Store {
+	sim, ok := data.(store.Simulator)
+	if !ok {
+		sim = simulator.NewStore(data, 0, 0)
+	}
+
+	s := &Store{Simulator: sim, clock: newClock(1)}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// LoadTick release ticks of data store when virtual time reach tick time
+func (s *Store) LoadTick(ctx context.Context, code string) (<-chan container.Tick, error) {
+	in, err := s.Simulator.LoadTick(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan container.Tick)
+	go func() {
+		defer close(ch)
+		for t := range in {
+			if !s.clock.wait(ctx, t.Date) {
+				return
+			}
+			select {
+			case ch <- t:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// Now is current virtual time of replay, zero before first tick
+func (s *Store) Now() time.Time {
+	s.clock.mu.Lock()
+	defer s.clock.mu.Unlock()
+	return s.clock.now()
+}
+
+// Pause stop virtual clock, ticks are held until Resume or Step
+func (s *Store) Pause() {
+	s.clock.pause()
+}
+
+func (s *Store) Resume() {
+	s.clock.resume()
+}
+
+// Step release next tick while paused
+// ticks of other codes which have same time are released together
+func (s *Store) Step() {
+	s.clock.step()
+}
+
+// Seek move virtual time to t, ticks before t are released without waiting
+// replay can not go back, so t before current time only change the clock
+func (s *Store) Seek(t time.Time) {
+	s.clock.seek(t)
+}
+
+// SetSpeed change replay speed from now
+func (s *Store) SetSpeed(speed float64) {
+	s.clock.setSpeed(speed)
+}
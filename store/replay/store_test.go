@@ -0,0 +1,156 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package replay
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gobenpark/trader/container"
+	"github.com/gobenpark/trader/store"
+	"github.com/gobenpark/trader/store/file"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ store.Simulator = (*Store)(nil)
+
+var start = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// data create file store which has ticks of code at start plus each interval
+func data(t *testing.T, intervals ...time.Duration) store.Store {
+	body := "Datetime,Price,Volume\n"
+	for k, i := range intervals {
+		body += fmt.Sprintf("%s,%d,1\n", start.Add(i).Format(time.RFC3339Nano), k)
+	}
+	path := filepath.Join(t.TempDir(), "tick.csv")
+	require.NoError(t, os.WriteFile(path, []byte(body), 0644))
+	return file.NewStore(0, 0, file.WithTickFile("code", path))
+}
+
+func receive(t *testing.T, ch <-chan container.Tick, timeout time.Duration) (container.Tick, bool) {
+	select {
+	case tick, ok := <-ch:
+		return tick, ok
+	case <-time.After(timeout):
+		return container.Tick{}, false
+	}
+}
+
+func TestStore_Speed(t *testing.T) {
+	tests := []struct {
+		name  string
+		speed float64
+		min   time.Duration
+		max   time.Duration
+	}{
+		{"as fast as possible", 0, 0, 100 * time.Millisecond},
+		{"real time", 1, 300 * time.Millisecond, time.Second},
+		{"twice faster", 2, 150 * time.Millisecond, 300 * time.Millisecond},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := NewStore(data(t, 0, 100*time.Millisecond, 200*time.Millisecond, 300*time.Millisecond), WithSpeed(test.speed))
+			ch, err := s.LoadTick(context.Background(), "code")
+			require.NoError(t, err)
+
+			now := time.Now()
+			var prices []float64
+			for tick := range ch {
+				prices = append(prices, tick.Price)
+			}
+			elapsed := time.Since(now)
+
+			assert.Equal(t, []float64{0, 1, 2, 3}, prices)
+			assert.GreaterOrEqual(t, int64(elapsed), int64(test.min))
+			assert.Less(t, int64(elapsed), int64(test.max))
+		})
+	}
+}
+
+func TestStore_PauseStep(t *testing.T) {
+	s := NewStore(data(t, 0, time.Hour, 2*time.Hour, 2*time.Hour), WithPause())
+	ch, err := s.LoadTick(context.Background(), "code")
+	require.NoError(t, err)
+
+	_, ok := receive(t, ch, 30*time.Millisecond)
+	assert.False(t, ok)
+
+	s.Step()
+	tick, ok := receive(t, ch, time.Second)
+	require.True(t, ok)
+	assert.Equal(t, float64(0), tick.Price)
+	assert.Equal(t, start, s.Now())
+
+	_, ok = receive(t, ch, 30*time.Millisecond)
+	assert.False(t, ok)
+
+	s.Step()
+	tick, ok = receive(t, ch, time.Second)
+	require.True(t, ok)
+	assert.Equal(t, float64(1), tick.Price)
+	assert.Equal(t, start.Add(time.Hour), s.Now())
+
+	s.SetSpeed(0)
+	s.Resume()
+	for _, price := range []float64{2, 3} {
+		tick, ok = receive(t, ch, time.Second)
+		require.True(t, ok)
+		assert.Equal(t, price, tick.Price)
+	}
+}
+
+func TestStore_Seek(t *testing.T) {
+	s := NewStore(data(t, 0, time.Hour, 2*time.Hour, 3*time.Hour))
+	ch, err := s.LoadTick(context.Background(), "code")
+	require.NoError(t, err)
+
+	tick, ok := receive(t, ch, time.Second)
+	require.True(t, ok)
+	assert.Equal(t, float64(0), tick.Price)
+
+	_, ok = receive(t, ch, 30*time.Millisecond)
+	assert.False(t, ok)
+
+	s.Seek(start.Add(2 * time.Hour))
+	for _, price := range []float64{1, 2} {
+		tick, ok = receive(t, ch, time.Second)
+		require.True(t, ok)
+		assert.Equal(t, price, tick.Price)
+	}
+
+	_, ok = receive(t, ch, 30*time.Millisecond)
+	assert.False(t, ok)
+}
+
+func TestStore_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := NewStore(data(t, 0, time.Hour))
+	ch, err := s.LoadTick(ctx, "code")
+	require.NoError(t, err)
+
+	_, ok := receive(t, ch, time.Second)
+	require.True(t, ok)
+	cancel()
+
+	_, ok = receive(t, ch, time.Second)
+	assert.False(t, ok)
+}
@@ -0,0 +1,332 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+// simulator package execute order locally for backtest and paper trading
+package simulator
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gobenpark/trader/container"
+	error2 "github.com/gobenpark/trader/error"
+	"github.com/gobenpark/trader/event"
+	"github.com/gobenpark/trader/order"
+	"github.com/gobenpark/trader/position"
+	uuid "github.com/satori/go.uuid"
+)
+
+// pending is copy of submitted order, broker keep changing original order
+type pending struct {
+	uuid      string
+	code      string
+	otype     order.OType
+	exec      order.ExecType
+	size      int64
+	price     float64
+	createdAt time.Time
+	status    order.Status
+	filled    int64
+}
+
+// Exchange match pending order with market data
+// Market order is filled at open of next candle, Close order at close,
+// Limit order when price cross limit price and Stop order when price touch stop price
+type Exchange struct {
+	mu         sync.Mutex
+	uid        string
+	cash       float64
+	commission float64
+	// volumeLimit ratio of candle volume which can be filled in one candle, zero is unlimited
+	volumeLimit float64
+	queue       []*pending
+	orders      map[string]*pending
+	positions   map[string]position.Position
+}
+
+// Option is setting of Exchange
+type Option func(*Exchange)
+
+// WithVolumeLimit limit filled size in one candle to ratio of candle volume
+// remain size is filled partially with following candles
+func WithVolumeLimit(ratio float64) Option {
+	return func(e *Exchange) {
+		e.volumeLimit = ratio
+	}
+}
+
+// NewExchange create exchange with initial cash and commission rate
+func NewExchange(cash int64, commission float64, opts ...Option) *Exchange {
+	e := &Exchange{
+		uid:        uuid.NewV4().String(),
+		cash:       float64(cash),
+		commission: commission,
+		orders:     make(map[string]*pending),
+		positions:  make(map[string]position.Position),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+func (e *Exchange) Order(o *order.Order) error {
+	switch o.ExecType {
+	case order.Market, order.Close, order.Limit, order.Stop:
+	default:
+		return error2.ErrNotSupportExec
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	p := &pending{
+		uuid:      o.UUID,
+		code:      o.Code,
+		otype:     o.OType,
+		exec:      o.ExecType,
+		size:      o.Size,
+		price:     o.Price,
+		createdAt: o.CreatedAt,
+		status:    order.Submitted,
+	}
+	e.orders[p.uuid] = p
+	e.queue = append(e.queue, p)
+	return nil
+}
+
+func (e *Exchange) Cancel(id string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	p, ok := e.orders[id]
+	if !ok || p.status != order.Submitted {
+		return error2.ErrNotExistOrder
+	}
+	p.status = order.Canceled
+	e.remove(id)
+	return nil
+}
+
+func (e *Exchange) Uid() string {
+	return e.uid
+}
+
+func (e *Exchange) Cash() int64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return int64(e.cash)
+}
+
+func (e *Exchange) SetCash(cash int64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.cash = float64(cash)
+}
+
+func (e *Exchange) Commission() float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.commission
+}
+
+func (e *Exchange) SetCommission(commission float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.commission = commission
+}
+
+func (e *Exchange) Positions() []position.Position {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var p []position.Position
+	for _, i := range e.positions {
+		p = append(p, i)
+	}
+	sort.Slice(p, func(i, j int) bool {
+		return p[i].Code < p[j].Code
+	})
+	return p
+}
+
+// OrderState never send event, order events of exchange are returned by Next
+func (e *Exchange) OrderState(ctx context.Context) (<-chan event.OrderEvent, error) {
+	ch := make(chan event.OrderEvent)
+	go func() {
+		<-ctx.Done()
+		close(ch)
+	}()
+	return ch, nil
+}
+
+func (e *Exchange) OrderInfo(id string) (*order.Order, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	p, ok := e.orders[id]
+	if !ok {
+		return nil, error2.ErrNotExistOrder
+	}
+
+	o := &order.Order{
+		OType:     p.otype,
+		ExecType:  p.exec,
+		Code:      p.code,
+		UUID:      p.uuid,
+		Size:      p.size,
+		Price:     p.price,
+		CreatedAt: p.createdAt,
+	}
+	switch p.status {
+	case order.Submitted:
+		o.Submit()
+	case order.Canceled:
+		o.Cancel()
+	case order.Completed:
+		o.Complete()
+	}
+	return o, nil
+}
+
+// Next execute pending orders of candle code with candle price
+// it returns "done" event of filled orders, "partial" event of orders limited by volume
+// and "cancel" event of orders which cash is not enough
+func (e *Exchange) Next(candle container.Candle) []event.OrderEvent {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var evts []event.OrderEvent
+	available := int64(-1)
+	if e.volumeLimit != 0 {
+		available = int64(candle.Volume * e.volumeLimit)
+	}
+
+	for _, p := range append([]*pending{}, e.queue...) {
+		if p.code != candle.Code || available == 0 {
+			continue
+		}
+
+		price, ok := fillPrice(p, candle)
+		if !ok {
+			continue
+		}
+
+		size := p.size - p.filled
+		if available > 0 && size > available {
+			size = available
+		}
+
+		evt := event.OrderEvent{
+			Event: event.Event{
+				EventType: "order",
+				Message:   "order event rise",
+			},
+			State: "done",
+			Oid:   p.uuid,
+			Size:  size,
+			Price: price,
+		}
+
+		commission, ok := e.execute(p, size, price, candle.Date)
+		evt.Commission = commission
+		switch {
+		case !ok:
+			evt.State = "cancel"
+			evt.Size = 0
+			p.status = order.Canceled
+			e.remove(p.uuid)
+		case p.filled < p.size:
+			evt.State = "partial"
+		default:
+			p.status = order.Completed
+			e.remove(p.uuid)
+		}
+
+		if available > 0 {
+			available -= evt.Size
+		}
+		evts = append(evts, evt)
+	}
+	return evts
+}
+
+// execute apply filled size to cash and position and return commission of it, false if cash is not enough
+func (e *Exchange) execute(p *pending, size int64, price float64, date time.Time) (float64, bool) {
+	value := price * float64(size)
+	commission := value * e.commission
+	signed := size
+
+	switch p.otype {
+	case order.Buy:
+		if e.cash < value+commission {
+			return 0, false
+		}
+		e.cash -= value + commission
+	case order.Sell:
+		e.cash += value - commission
+		signed = -size
+	}
+	p.filled += size
+
+	pos, ok := e.positions[p.code]
+	if !ok {
+		pos = position.Position{Code: p.code}
+	}
+	pos.Update(signed, price, commission, date)
+
+	if pos.Size == 0 {
+		delete(e.positions, p.code)
+	} else {
+		e.positions[p.code] = pos
+	}
+	return commission, true
+}
+
+func (e *Exchange) remove(id string) {
+	for k, v := range e.queue {
+		if v.uuid == id {
+			e.queue = append(e.queue[:k], e.queue[k+1:]...)
+			return
+		}
+	}
+}
+
+// fillPrice decide execution price of pending order in candle
+// if candle open already pass limit or stop price, order is filled with open price
+func fillPrice(p *pending, candle container.Candle) (float64, bool) {
+	buy := p.otype == order.Buy
+	switch p.exec {
+	case order.Market:
+		return candle.Open, true
+	case order.Close:
+		return candle.Close, true
+	case order.Limit:
+		if buy && candle.Low <= p.price {
+			return math.Min(candle.Open, p.price), true
+		}
+		if !buy && candle.High >= p.price {
+			return math.Max(candle.Open, p.price), true
+		}
+	case order.Stop:
+		if buy && candle.High >= p.price {
+			return math.Max(candle.Open, p.price), true
+		}
+		if !buy && candle.Low <= p.price {
+			return math.Min(candle.Open, p.price), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,225 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package simulator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gobenpark/trader/container"
+	"github.com/gobenpark/trader/order"
+	"github.com/gobenpark/trader/store"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ store.Simulator = (*Store)(nil)
+
+func candle(open, high, low, close float64) container.Candle {
+	return container.Candle{
+		Code:   "code",
+		Open:   open,
+		High:   high,
+		Low:    low,
+		Close:  close,
+		Volume: 100,
+		Date:   time.Now(),
+	}
+}
+
+func TestExchange_Next(t *testing.T) {
+	tests := []struct {
+		name   string
+		order  *order.Order
+		candle container.Candle
+		filled bool
+		cash   int64
+	}{
+		{
+			"market buy fill at open",
+			&order.Order{OType: order.Buy, ExecType: order.Market, Code: "code", UUID: "1", Size: 10},
+			candle(100, 120, 90, 110),
+			true,
+			9000,
+		},
+		{
+			"close buy fill at close",
+			&order.Order{OType: order.Buy, ExecType: order.Close, Code: "code", UUID: "1", Size: 10},
+			candle(100, 120, 90, 110),
+			true,
+			8900,
+		},
+		{
+			"limit buy fill at limit price",
+			&order.Order{OType: order.Buy, ExecType: order.Limit, Code: "code", UUID: "1", Size: 10, Price: 95},
+			candle(100, 120, 90, 110),
+			true,
+			9050,
+		},
+		{
+			"limit buy fill at open when gap down",
+			&order.Order{OType: order.Buy, ExecType: order.Limit, Code: "code", UUID: "1", Size: 10, Price: 95},
+			candle(80, 120, 70, 110),
+			true,
+			9200,
+		},
+		{
+			"limit buy not filled",
+			&order.Order{OType: order.Buy, ExecType: order.Limit, Code: "code", UUID: "1", Size: 10, Price: 80},
+			candle(100, 120, 90, 110),
+			false,
+			10000,
+		},
+		{
+			"stop buy triggered",
+			&order.Order{OType: order.Buy, ExecType: order.Stop, Code: "code", UUID: "1", Size: 10, Price: 115},
+			candle(100, 120, 90, 110),
+			true,
+			8850,
+		},
+		{
+			"stop buy not triggered",
+			&order.Order{OType: order.Buy, ExecType: order.Stop, Code: "code", UUID: "1", Size: 10, Price: 130},
+			candle(100, 120, 90, 110),
+			false,
+			10000,
+		},
+		{
+			"limit sell fill at limit price",
+			&order.Order{OType: order.Sell, ExecType: order.Limit, Code: "code", UUID: "1", Size: 10, Price: 115},
+			candle(100, 120, 90, 110),
+			true,
+			11150,
+		},
+		{
+			"stop sell triggered",
+			&order.Order{OType: order.Sell, ExecType: order.Stop, Code: "code", UUID: "1", Size: 10, Price: 95},
+			candle(100, 120, 90, 110),
+			true,
+			10950,
+		},
+		{
+			"other code is not filled",
+			&order.Order{OType: order.Buy, ExecType: order.Market, Code: "other", UUID: "1", Size: 10},
+			candle(100, 120, 90, 110),
+			false,
+			10000,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := NewExchange(10000, 0)
+			assert.NoError(t, e.Order(test.order))
+
+			evts := e.Next(test.candle)
+			if test.filled {
+				assert.Len(t, evts, 1)
+				assert.Equal(t, "done", evts[0].State)
+				assert.Equal(t, test.order.UUID, evts[0].Oid)
+			} else {
+				assert.Len(t, evts, 0)
+			}
+			assert.Equal(t, test.cash, e.Cash())
+		})
+	}
+}
+
+func TestExchange_Commission(t *testing.T) {
+	e := NewExchange(10000, 0.01)
+	assert.NoError(t, e.Order(&order.Order{OType: order.Buy, ExecType: order.Market, Code: "code", UUID: "1", Size: 10}))
+	evts := e.Next(candle(100, 100, 100, 100))
+	assert.Equal(t, int64(8990), e.Cash())
+	assert.Equal(t, float64(10), evts[0].Commission)
+
+	assert.NoError(t, e.Order(&order.Order{OType: order.Sell, ExecType: order.Market, Code: "code", UUID: "2", Size: 10}))
+	evts = e.Next(candle(200, 200, 200, 200))
+	assert.Equal(t, int64(10970), e.Cash())
+	assert.Equal(t, float64(20), evts[0].Commission)
+	assert.Len(t, e.Positions(), 0)
+}
+
+func TestExchange_Positions(t *testing.T) {
+	e := NewExchange(10000, 0)
+	assert.NoError(t, e.Order(&order.Order{OType: order.Buy, ExecType: order.Market, Code: "code", UUID: "1", Size: 10}))
+	e.Next(candle(100, 100, 100, 100))
+	assert.NoError(t, e.Order(&order.Order{OType: order.Buy, ExecType: order.Market, Code: "code", UUID: "2", Size: 30}))
+	e.Next(candle(200, 200, 200, 200))
+
+	p := e.Positions()
+	assert.Len(t, p, 1)
+	assert.Equal(t, int64(40), p[0].Size)
+	assert.Equal(t, float64(175), p[0].Price)
+
+	assert.NoError(t, e.Order(&order.Order{OType: order.Sell, ExecType: order.Market, Code: "code", UUID: "3", Size: 50}))
+	e.Next(candle(100, 100, 100, 100))
+
+	p = e.Positions()
+	assert.Len(t, p, 1)
+	assert.Equal(t, int64(-10), p[0].Size)
+	assert.Equal(t, float64(100), p[0].Price)
+}
+
+func TestExchange_NotEnoughCash(t *testing.T) {
+	e := NewExchange(100, 0)
+	assert.NoError(t, e.Order(&order.Order{OType: order.Buy, ExecType: order.Market, Code: "code", UUID: "1", Size: 10}))
+
+	evts := e.Next(candle(100, 100, 100, 100))
+	assert.Len(t, evts, 1)
+	assert.Equal(t, "cancel", evts[0].State)
+	assert.Equal(t, int64(100), e.Cash())
+
+	o, err := e.OrderInfo("1")
+	assert.NoError(t, err)
+	assert.Equal(t, order.Canceled, o.Status())
+}
+
+func TestExchange_Cancel(t *testing.T) {
+	e := NewExchange(10000, 0)
+	assert.NoError(t, e.Order(&order.Order{OType: order.Buy, ExecType: order.Limit, Code: "code", UUID: "1", Size: 10, Price: 10}))
+	assert.NoError(t, e.Cancel("1"))
+	assert.Error(t, e.Cancel("1"))
+	assert.Len(t, e.Next(candle(5, 5, 5, 5)), 0)
+}
+
+func TestExchange_VolumeLimit(t *testing.T) {
+	e := NewExchange(10000, 0, WithVolumeLimit(0.1))
+	assert.NoError(t, e.Order(&order.Order{OType: order.Buy, ExecType: order.Market, Code: "code", UUID: "1", Size: 15}))
+	assert.NoError(t, e.Order(&order.Order{OType: order.Buy, ExecType: order.Market, Code: "code", UUID: "2", Size: 5}))
+
+	evts := e.Next(candle(100, 100, 100, 100))
+	assert.Len(t, evts, 1)
+	assert.Equal(t, "partial", evts[0].State)
+	assert.Equal(t, int64(10), evts[0].Size)
+	assert.Equal(t, float64(100), evts[0].Price)
+
+	evts = e.Next(candle(110, 110, 110, 110))
+	assert.Len(t, evts, 2)
+	assert.Equal(t, "done", evts[0].State)
+	assert.Equal(t, int64(5), evts[0].Size)
+	assert.Equal(t, "done", evts[1].State)
+	assert.Equal(t, "2", evts[1].Oid)
+	assert.Equal(t, int64(5), evts[1].Size)
+
+	p := e.Positions()
+	assert.Equal(t, int64(20), p[0].Size)
+	assert.Equal(t, int64(10000-1000-1100), e.Cash())
+}
+
+func TestExchange_NotSupportExec(t *testing.T) {
+	e := NewExchange(10000, 0)
+	err := e.Order(&order.Order{OType: order.Buy, ExecType: order.StopTrail, Code: "code", UUID: "1", Size: 10})
+	assert.Error(t, err)
+}
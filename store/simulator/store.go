@@ -0,0 +1,47 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package simulator
+
+import (
+	"context"
+	"time"
+
+	"github.com/gobenpark/trader/container"
+	"github.com/gobenpark/trader/store"
+)
+
+// Store load market data from other store, but orders are executed in Exchange
+// it makes paper trading with live data or backtest with history data
+type Store struct {
+	*Exchange
+	data store.Store
+}
+
+// NewStore create simulating store which market data come from data store
+func NewStore(data store.Store, cash int64, commission float64, opts ...Option) *Store {
+	return &Store{
+		Exchange: NewExchange(cash, commission, opts...),
+		data:     data,
+	}
+}
+
+func (s *Store) LoadHistory(ctx context.Context, code string, d time.Duration) ([]container.Candle, error) {
+	return s.data.LoadHistory(ctx, code, d)
+}
+
+func (s *Store) LoadTick(ctx context.Context, code string) (<-chan container.Tick, error) {
+	return s.data.LoadTick(ctx, code)
+}
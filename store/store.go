@@ -24,3 +24,13 @@ type Store interface {
 	OrderState(ctx context.Context) (<-chan event.OrderEvent, error)
 	OrderInfo(id string) (*order.Order, error)
 }
+
+// Simulator is store which execute order by itself with market data instead of exchange
+// broker feed every market data through Next, and order events which are raised by the data
+// are returned directly so backtest can be deterministic
+type Simulator interface {
+	Store
+	SetCash(cash int64)
+	SetCommission(commission float64)
+	Next(candle container.Candle) []event.OrderEvent
+}
@@ -21,11 +21,14 @@ import (
 	"github.com/gobenpark/trader/broker"
 	"github.com/gobenpark/trader/container"
 	"github.com/gobenpark/trader/order"
+	"github.com/gobenpark/trader/trade"
 )
 
 type Engine struct {
 	*broker.Broker
 	Sts []Strategy
+	// Steps is channel of steps delivered with data containers
+	Steps chan *Step
 }
 
 func (s *Engine) Start(ctx context.Context, data chan container.Container) {
@@ -34,9 +37,9 @@ func (s *Engine) Start(ctx context.Context, data chan container.Container) {
 		for {
 			select {
 			case i := <-data:
-				for _, st := range s.Sts {
-					st.Next(s.Broker, i)
-				}
+				s.Next(i)
+			case i := <-s.Steps:
+				s.Step(i)
 			case <-ctx.Done():
 				break Done
 			}
@@ -44,11 +47,49 @@ func (s *Engine) Start(ctx context.Context, data chan container.Container) {
 	}()
 }
 
+// SetFeeds give feeds to every strategy which implements FeedSetter
+func (s *Engine) SetFeeds(feeds Feeds) {
+	for _, st := range s.Sts {
+		if fs, ok := st.(FeedSetter); ok {
+			fs.SetFeeds(feeds)
+		}
+	}
+}
+
+// Next deliver data container to every strategy
+func (s *Engine) Next(c container.Container) {
+	for _, st := range s.Sts {
+		st.Next(s.Broker, c)
+	}
+}
+
+// Step deliver step to every strategy, strategy which is not StepStrategy gets every container of step
+func (s *Engine) Step(step *Step) {
+	for _, st := range s.Sts {
+		if ss, ok := st.(StepStrategy); ok {
+			ss.NextStep(s.Broker, step)
+			continue
+		}
+		for _, c := range step.Containers {
+			st.Next(s.Broker, c)
+		}
+	}
+}
+
 func (s *Engine) Listen(e interface{}) {
 	switch et := e.(type) {
 	case *order.Order:
 		for _, strategy := range s.Sts {
 			strategy.NotifyOrder(et)
 		}
+	case *trade.Trade:
+		for _, strategy := range s.Sts {
+			strategy.NotifyTrade(et)
+		}
+	case *broker.Fund:
+		for _, strategy := range s.Sts {
+			strategy.NotifyCashValue(et.Cash, et.Value)
+			strategy.NotifyFund(et)
+		}
 	}
 }
@@ -8,8 +8,11 @@ import (
 	broker "github.com/gobenpark/trader/broker"
 	container "github.com/gobenpark/trader/container"
 	order "github.com/gobenpark/trader/order"
+	strategy "github.com/gobenpark/trader/strategy"
+	trade "github.com/gobenpark/trader/trade"
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
+	time "time"
 )
 
 // MockStrategy is a mock of Strategy interface
@@ -60,37 +63,158 @@ func (mr *MockStrategyMockRecorder) NotifyOrder(o interface{}) *gomock.Call {
 }
 
 // NotifyTrade mocks base method
-func (m *MockStrategy) NotifyTrade() {
+func (m *MockStrategy) NotifyTrade(t *trade.Trade) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "NotifyTrade")
+	m.ctrl.Call(m, "NotifyTrade", t)
 }
 
 // NotifyTrade indicates an expected call of NotifyTrade
-func (mr *MockStrategyMockRecorder) NotifyTrade() *gomock.Call {
+func (mr *MockStrategyMockRecorder) NotifyTrade(t interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyTrade", reflect.TypeOf((*MockStrategy)(nil).NotifyTrade))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyTrade", reflect.TypeOf((*MockStrategy)(nil).NotifyTrade), t)
 }
 
 // NotifyCashValue mocks base method
-func (m *MockStrategy) NotifyCashValue() {
+func (m *MockStrategy) NotifyCashValue(cash, value float64) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "NotifyCashValue")
+	m.ctrl.Call(m, "NotifyCashValue", cash, value)
 }
 
 // NotifyCashValue indicates an expected call of NotifyCashValue
-func (mr *MockStrategyMockRecorder) NotifyCashValue() *gomock.Call {
+func (mr *MockStrategyMockRecorder) NotifyCashValue(cash, value interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyCashValue", reflect.TypeOf((*MockStrategy)(nil).NotifyCashValue))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyCashValue", reflect.TypeOf((*MockStrategy)(nil).NotifyCashValue), cash, value)
 }
 
 // NotifyFund mocks base method
-func (m *MockStrategy) NotifyFund() {
+func (m *MockStrategy) NotifyFund(fund *broker.Fund) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "NotifyFund")
+	m.ctrl.Call(m, "NotifyFund", fund)
 }
 
 // NotifyFund indicates an expected call of NotifyFund
-func (mr *MockStrategyMockRecorder) NotifyFund() *gomock.Call {
+func (mr *MockStrategyMockRecorder) NotifyFund(fund interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyFund", reflect.TypeOf((*MockStrategy)(nil).NotifyFund))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyFund", reflect.TypeOf((*MockStrategy)(nil).NotifyFund), fund)
+}
+
+// MockFeeds is a mock of Feeds interface
+type MockFeeds struct {
+	ctrl     *gomock.Controller
+	recorder *MockFeedsMockRecorder
+}
+
+// MockFeedsMockRecorder is the mock recorder for MockFeeds
+type MockFeedsMockRecorder struct {
+	mock *MockFeeds
+}
+
+// NewMockFeeds creates a new mock instance
+func NewMockFeeds(ctrl *gomock.Controller) *MockFeeds {
+	mock := &MockFeeds{ctrl: ctrl}
+	mock.recorder = &MockFeedsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockFeeds) EXPECT() *MockFeedsMockRecorder {
+	return m.recorder
+}
+
+// Feed mocks base method
+func (m *MockFeeds) Feed(code string, level time.Duration) container.Container {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Feed", code, level)
+	ret0, _ := ret[0].(container.Container)
+	return ret0
+}
+
+// Feed indicates an expected call of Feed
+func (mr *MockFeedsMockRecorder) Feed(code, level interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Feed", reflect.TypeOf((*MockFeeds)(nil).Feed), code, level)
+}
+
+// Bar mocks base method
+func (m *MockFeeds) Bar(code string, spec container.BarSpec) container.Container {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Bar", code, spec)
+	ret0, _ := ret[0].(container.Container)
+	return ret0
+}
+
+// Bar indicates an expected call of Bar
+func (mr *MockFeedsMockRecorder) Bar(code, spec interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Bar", reflect.TypeOf((*MockFeeds)(nil).Bar), code, spec)
+}
+
+// MockFeedSetter is a mock of FeedSetter interface
+type MockFeedSetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockFeedSetterMockRecorder
+}
+
+// MockFeedSetterMockRecorder is the mock recorder for MockFeedSetter
+type MockFeedSetterMockRecorder struct {
+	mock *MockFeedSetter
+}
+
+// NewMockFeedSetter creates a new mock instance
+func NewMockFeedSetter(ctrl *gomock.Controller) *MockFeedSetter {
+	mock := &MockFeedSetter{ctrl: ctrl}
+	mock.recorder = &MockFeedSetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockFeedSetter) EXPECT() *MockFeedSetterMockRecorder {
+	return m.recorder
+}
+
+// SetFeeds mocks base method
+func (m *MockFeedSetter) SetFeeds(feeds strategy.Feeds) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetFeeds", feeds)
+}
+
+// SetFeeds indicates an expected call of SetFeeds
+func (mr *MockFeedSetterMockRecorder) SetFeeds(feeds interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFeeds", reflect.TypeOf((*MockFeedSetter)(nil).SetFeeds), feeds)
+}
+
+// MockStepStrategy is a mock of StepStrategy interface
+type MockStepStrategy struct {
+	ctrl     *gomock.Controller
+	recorder *MockStepStrategyMockRecorder
+}
+
+// MockStepStrategyMockRecorder is the mock recorder for MockStepStrategy
+type MockStepStrategyMockRecorder struct {
+	mock *MockStepStrategy
+}
+
+// NewMockStepStrategy creates a new mock instance
+func NewMockStepStrategy(ctrl *gomock.Controller) *MockStepStrategy {
+	mock := &MockStepStrategy{ctrl: ctrl}
+	mock.recorder = &MockStepStrategyMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockStepStrategy) EXPECT() *MockStepStrategyMockRecorder {
+	return m.recorder
+}
+
+// NextStep mocks base method
+func (m *MockStepStrategy) NextStep(broker *broker.Broker, step *strategy.Step) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "NextStep", broker, step)
+}
+
+// NextStep indicates an expected call of NextStep
+func (mr *MockStepStrategyMockRecorder) NextStep(broker, step interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NextStep", reflect.TypeOf((*MockStepStrategy)(nil).NextStep), broker, step)
 }
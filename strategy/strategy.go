@@ -18,9 +18,12 @@ package strategy
 //go:generate mockgen -source=./strategy.go -destination=./mock/mock_strategy.go
 
 import (
+	"time"
+
 	"github.com/gobenpark/trader/broker"
 	"github.com/gobenpark/trader/container"
 	"github.com/gobenpark/trader/order"
+	"github.com/gobenpark/trader/trade"
 )
 
 type Strategy interface {
@@ -28,7 +31,41 @@ type Strategy interface {
 
 	//NotifyOrder is when event rise order then called
 	NotifyOrder(o *order.Order)
-	NotifyTrade()
-	NotifyCashValue()
-	NotifyFund()
+	//NotifyTrade is called when trade is opened, updated and closed
+	NotifyTrade(t *trade.Trade)
+	//NotifyCashValue is called with cash and total value of account after every bar and execution
+	NotifyCashValue(cash, value float64)
+	//NotifyFund is called with same timing of NotifyCashValue with fund snapshot
+	NotifyFund(fund *broker.Fund)
+}
+
+// Feeds is accessor of containers of every code and level held by cerebro
+type Feeds interface {
+	// Feed is container of time level of code, nil if it does not exist
+	Feed(code string, level time.Duration) container.Container
+	// Bar is container of bar spec of code, nil if it does not exist
+	Bar(code string, spec container.BarSpec) container.Container
+}
+
+// FeedSetter is implemented by strategy which reads containers of other level or code in Next
+// containers of higher level are updated before container of lower level is delivered for same time
+type FeedSetter interface {
+	SetFeeds(feeds Feeds)
+}
+
+// Step is candles of every code closed at same time and level
+type Step struct {
+	// Date is end time of period
+	Date  time.Time
+	Level time.Duration
+	// Containers is containers updated at this step
+	Containers []container.Container
+	// Missing is codes which have no candle at this step
+	Missing []string
+}
+
+// StepStrategy is implemented by strategy which wants candles of every code together
+// when step clock of cerebro is enabled, NextStep is called once per step instead of Next
+type StepStrategy interface {
+	NextStep(broker *broker.Broker, step *Step)
 }
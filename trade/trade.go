@@ -0,0 +1,147 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package trade
+
+import (
+	"math"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+type Status int
+
+const (
+	Open Status = iota + 1
+	Closed
+)
+
+// Trade is round trip of code from flat position to flat position
+// Size is positive for long and negative for short, EntryPrice is volume weighted average entry price
+type Trade struct {
+	ID     string `json:"id"`
+	Code   string `json:"code"`
+	Status Status `json:"status"`
+	Long   bool   `json:"long"`
+	Size   int64  `json:"size"`
+	// MaxSize largest absolute size while trade is open
+	MaxSize    int64   `json:"maxSize"`
+	EntryPrice float64 `json:"entryPrice"`
+	// ExitPrice volume weighted average price of reducing executions
+	ExitPrice float64 `json:"exitPrice"`
+	// PnL profit of closed size without commission
+	PnL        float64 `json:"pnl"`
+	Commission float64 `json:"commission"`
+	// BarLen count of bars while trade is open
+	BarLen   int       `json:"barLen"`
+	OpenedAt time.Time `json:"openedAt"`
+	ClosedAt time.Time `json:"closedAt"`
+	// UpdatedAt time of latest execution
+	UpdatedAt time.Time `json:"updatedAt"`
+	exited    int64
+}
+
+// New open trade with first execution, size is positive for buy and negative for sell
+func New(code string, size int64, price, commission float64, date time.Time) *Trade {
+	return &Trade{
+		ID:         uuid.NewV4().String(),
+		Code:       code,
+		Status:     Open,
+		Long:       size > 0,
+		Size:       size,
+		MaxSize:    abs(size),
+		EntryPrice: price,
+		Commission: commission,
+		OpenedAt:   date,
+		UpdatedAt:  date,
+	}
+}
+
+// Update apply execution to open trade
+// if execution reduce size over zero, trade is closed and left size of opposite direction is returned
+// left size should open new trade with remain commission
+func (t *Trade) Update(size int64, price, commission float64, date time.Time) (int64, float64) {
+	t.UpdatedAt = date
+	current := abs(t.Size)
+	amount := abs(size)
+
+	if (t.Size > 0) == (size > 0) {
+		t.EntryPrice = (t.EntryPrice*float64(current) + price*float64(amount)) / float64(current+amount)
+		t.Size += size
+		if abs(t.Size) > t.MaxSize {
+			t.MaxSize = abs(t.Size)
+		}
+		t.Commission += commission
+		return 0, 0
+	}
+
+	closed := current
+	if amount < current {
+		closed = amount
+	}
+	rate := float64(closed) / float64(amount)
+
+	if t.Long {
+		t.PnL += (price - t.EntryPrice) * float64(closed)
+	} else {
+		t.PnL += (t.EntryPrice - price) * float64(closed)
+	}
+	t.ExitPrice = (t.ExitPrice*float64(t.exited) + price*float64(closed)) / float64(t.exited+closed)
+	t.exited += closed
+	t.Commission += commission * rate
+
+	if t.Long {
+		t.Size -= closed
+	} else {
+		t.Size += closed
+	}
+
+	if t.Size == 0 {
+		t.Status = Closed
+		t.ClosedAt = date
+	}
+
+	left := amount - closed
+	if left == 0 {
+		return 0, 0
+	}
+	if size < 0 {
+		left = -left
+	}
+	return left, commission * (1 - rate)
+}
+
+// IsOpen is true until trade is closed
+func (t Trade) IsOpen() bool {
+	return t.Status == Open
+}
+
+// PnLComm is profit of trade with commission
+func (t Trade) PnLComm() float64 {
+	return t.PnL - t.Commission
+}
+
+// Duration is holding time of trade, until latest execution if trade is not closed
+func (t Trade) Duration() time.Duration {
+	if t.IsOpen() {
+		return t.UpdatedAt.Sub(t.OpenedAt)
+	}
+	return t.ClosedAt.Sub(t.OpenedAt)
+}
+
+func abs(size int64) int64 {
+	return int64(math.Abs(float64(size)))
+}
@@ -0,0 +1,52 @@
+/*
+ *  Copyright 2021 The Trader Authors
+ *
+ *  Licensed under the GNU General Public License v3.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      <https:fsf.org/>
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package trade
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrade_Update(t *testing.T) {
+	now := time.Now()
+	tr := New("code", -10, 100, 1, now)
+	assert.False(t, tr.Long)
+
+	left, commission := tr.Update(-10, 120, 1, now)
+	assert.Equal(t, int64(0), left)
+	assert.Equal(t, float64(0), commission)
+	assert.Equal(t, int64(-20), tr.Size)
+	assert.Equal(t, int64(20), tr.MaxSize)
+	assert.Equal(t, float64(110), tr.EntryPrice)
+
+	left, _ = tr.Update(5, 100, 1, now.Add(time.Hour))
+	assert.Equal(t, int64(0), left)
+	assert.True(t, tr.IsOpen())
+	assert.Equal(t, float64(50), tr.PnL)
+
+	left, commission = tr.Update(20, 90, 4, now.Add(2*time.Hour))
+	assert.Equal(t, int64(5), left)
+	assert.Equal(t, float64(1), commission)
+	assert.False(t, tr.IsOpen())
+	assert.Equal(t, int64(0), tr.Size)
+	assert.Equal(t, float64(350), tr.PnL)
+	assert.Equal(t, 92.5, tr.ExitPrice)
+	assert.Equal(t, float64(6), tr.Commission)
+	assert.Equal(t, float64(344), tr.PnLComm())
+	assert.Equal(t, 2*time.Hour, tr.Duration())
+}